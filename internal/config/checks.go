@@ -1,26 +1,26 @@
 package config
 
 import (
-	"context"
 	"fmt"
-	"os/exec"
-	"path"
 	"strings"
 	"time"
 )
 
+// RunSlurmCommand runs a Slurm binary with the given arguments and timeout, and returns its stdout.
+// The config package can't depend on the model, so the model sets this to its command runner, and
+// the checks below are counted, logged and recorded like all other commands.
+var RunSlurmCommand func(binary string, args []string, timeout time.Duration) ([]byte, error)
+
 // Check whether we should enable sacctmgr-related features, by
 // making a call to 'sacctmgr show configuration' and checking its
 // exit code.
 func checkIfSacctMgrIsAvailable() {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+	if Backend == BACKEND_SLURMRESTD {
+		checkIfSlurmdbIsAvailableViaSlurmRestd()
+		return
+	}
 
-	cmd := exec.CommandContext(ctx,
-		path.Join(SlurmBinariesPath, "sacctmgr"),
-		strings.Split("show cluster", " ")...,
-	)
-	_, err := cmd.Output()
+	_, err := RunSlurmCommand("sacctmgr", []string{"show", "cluster"}, 1*time.Second)
 	if err != nil {
 		SacctEnabled = false
 	} else {
@@ -30,18 +30,17 @@ func checkIfSacctMgrIsAvailable() {
 
 // Check whether the cluster is reachable with 'scontrol ping'
 func checkIfClusterIsReachable() error {
-	ctx, cancel := context.WithTimeout(context.Background(), RequestTimeout)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx,
-		path.Join(SlurmBinariesPath, "scontrol"), "ping",
-	)
-	rawOut, err := cmd.CombinedOutput()
-	if ctx.Err() == context.DeadlineExceeded {
-		return fmt.Errorf("cluster did not respond within configured timeout %s", RequestTimeout)
+	if Backend == BACKEND_SLURMRESTD {
+		return checkIfSlurmRestdIsReachable()
 	}
+
+	out, err := RunSlurmCommand("scontrol", []string{"ping"}, RequestTimeout)
 	if err != nil {
-		return fmt.Errorf("failed to connect to Slurm: %s", string(rawOut))
+		// 'scontrol ping' reports which controllers are down on stdout
+		if status := strings.TrimSpace(string(out)); status != "" {
+			return fmt.Errorf("%v: %s", err, status)
+		}
+		return err
 	}
 	return nil
 }
@@ -50,13 +49,11 @@ func checkIfClusterIsReachable() error {
 // but is a one-off call at initialization and makes more sense
 // in checks.
 func getSchedulerInfoWithTimeout(timeout time.Duration) (schedulerHostName, clusterName, slurmVersion string) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-	cmd := exec.CommandContext(ctx,
-		path.Join(SlurmBinariesPath, "scontrol"),
-		"show", "config",
-	)
-	out, err := cmd.Output()
+	if Backend == BACKEND_SLURMRESTD {
+		return getSchedulerInfoViaSlurmRestd(timeout)
+	}
+
+	out, err := RunSlurmCommand("scontrol", []string{"show", "config"}, timeout)
 	if err != nil {
 		schedulerHostName = "(failed to fetch scheduler info)"
 	}
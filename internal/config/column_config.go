@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"log"
+	"slices"
 	"strings"
 )
 
@@ -31,6 +32,49 @@ func GetColumnFields(columnConfigs *[]ColumnConfig) (columns []string) {
 	return
 }
 
+// FormatColumnConfigLine formats column configs back into the format of the column config flags
+func FormatColumnConfigLine(columnConfigs []ColumnConfig) string {
+	var parts []string
+	for _, col := range columnConfigs {
+		parts = append(parts, col.RawName)
+	}
+	return strings.Join(parts, ",")
+}
+
+// withFixedColumns adds the fixed columns of a view in front of a column config line
+func withFixedColumns(fixedColumns, rawColumns string) string {
+	if strings.TrimSpace(rawColumns) == "" {
+		return fixedColumns
+	}
+	return fixedColumns + "," + rawColumns
+}
+
+// withClusterColumn adds the Cluster column in front of a column config line, unless it is already included
+func withClusterColumn(rawColumns string) string {
+	if slices.Contains(availableColumns(rawColumns), CLUSTER_COLUMN) {
+		return rawColumns
+	}
+	if strings.TrimSpace(rawColumns) == "" {
+		return CLUSTER_COLUMN
+	}
+	return CLUSTER_COLUMN + "," + rawColumns
+}
+
+// availableColumns lists the individual fields of the given column config lines, without duplicates
+func availableColumns(rawColumns ...string) (fields []string) {
+	for _, raw := range rawColumns {
+		raw = strings.ReplaceAll(raw, "++", "")
+		raw = strings.ReplaceAll(raw, "//", ",")
+		for _, field := range strings.Split(raw, ",") {
+			field = strings.TrimSpace(field)
+			if field != "" && !slices.Contains(fields, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return
+}
+
 func parseColumnConfigLine(input string) (*[]ColumnConfig, error) {
 	if input == "" {
 		return nil, errors.New("cannot parse empty column config")
@@ -6,7 +6,13 @@ import (
 	"log"
 	"os"
 	"os/user"
+	"path/filepath"
+	"slices"
+	"sync"
 	"time"
+
+	"github.com/antvirf/stui/internal/audit"
+	"github.com/antvirf/stui/internal/recorder"
 )
 
 var (
@@ -23,21 +29,51 @@ var (
 	LogLevel               int           = 2
 	ShowAllColumns         bool          = false
 	ConfigDirPath          string        = DEFAULT_CONFIG_LOCATION
+	Backend                string        = BACKEND_CLI
+	SlurmRestdURL          string        = ""
+	SlurmRestdAPIVersion   string        = "v0.0.41"
+	SlurmRestdTokenFile    string        = ""
+	RecordDir              string        = ""
+	SacctUsers             string        = ""
+	SacctAccounts          string        = ""
+	SacctStates            string        = ""
+	ReplayDir              string        = ""
+	AuditLog               string        = "" // Defaults to AUDIT_LOG_FILE_NAME in the config dir
+	ActiveProfile          string        = ""
+	Clusters               string        = ""
+	Clipboard              string        = CLIPBOARD_AUTO
+
+	// Options of the 'export' subcommand, which writes a view to stdout instead of starting the UI
+	ExportMode   bool   = false
+	ExportView   string = "nodes"
+	ExportFormat string = "csv"
+	ExportState  string = ""
+	ExportSearch string = ""
 
 	// Raw config options are not exposed to other modules, but pre-parsed by the config module
 	rawNodeViewColumns  string = "CPULoad//CPUAlloc//CPUTot,AllocMem//RealMemory,CfgTRES++,Reason"
 	rawJobViewColumns   string = "UserId,JobName++,RunTime,NodeList,QOS,NumCPUs,Mem"
+	rawSstatViewColumns string = "AveCPU,AveRSS,MaxRSS,MaxDiskRead,MaxDiskWrite"
 	rawSacctViewColumns string = "QOS,Account,User,JobName++,NodeList,ReqCPUS//AllocCPUS,ReqMem,Elapsed,ExitCode,ReqTRES,AllocTRES++,Comment++,SubmitLine++"
 
 	NodeViewColumns  *[]ColumnConfig
 	JobViewColumns   *[]ColumnConfig
 	SacctViewColumns *[]ColumnConfig
+	SstatViewColumns *[]ColumnConfig
+
+	// Fields offered in the column chooser, including those in the initial column config
+	AvailableNodeViewColumns  []string
+	AvailableJobViewColumns   []string
+	AvailableSacctViewColumns []string
 
 	// Derived config options
-	SacctEnabled bool = false
+	SacctEnabled    bool   = false
+	SlurmRestdToken string = "" // Read from SLURM_JWT or SlurmRestdTokenFile
 
 	// Internal configs
 	SacctMgrCurrentEntity          string = "Account" // Default starting point
+	SacctStartTime                 string = ""        // Absolute sacct time range, if set, overrides LoadSacctDataFrom
+	SacctEndTime                   string = ""
 	NodeStateCurrentChoice         string = ALL_CATEGORIES_OPTION
 	JobStateCurrentChoice          string = ALL_CATEGORIES_OPTION
 	NodeViewColumnsPartitionIndex  int
@@ -55,40 +91,15 @@ var (
 	ClusterName           string = "unknown"
 	SchedulerHostName     string = "unknown"
 	SchedulerSlurmVersion string = "unknown"
+
+	// Clusters whose Slurm does not support JSON output, by the name given with '-M', or empty for
+	// the local cluster. Reset when switching profiles.
+	jsonOutputUnavailable   = map[string]bool{}
+	jsonOutputUnavailableMu sync.Mutex
 )
 
 const (
-	STUI_VERSION       = "0.9.0"
-	KEYBOARD_SHORTCUTS = `GENERAL SHORTCUTS
-1        Switch to Nodes view (scontrol)
-2        Switch to Jobs view (scontrol)
-3        Switch to Jobs accounting view (sacct)
-4        Switch to Accounting Manager view (sacctmgr)
-5        Switch to Scheduler view (sdiag)
-k/j      Move selection up/down in table view
-h/l      Scroll left/right in table view
-Arrows   Scroll up/down/left/right in table view
-?        Show this help
-Ctrl+R   Refresh currently visible data
-Ctrl+C   Exit
-o        Sort table by column
-
-SHORTCUTS IN JOB/NODE VIEW
-/        Open search bar to filter rows by regex, 'esc' to close, 'enter' to go back to table
-p        Focus on partition selector, 'esc' to close
-s        Focus on state selector, 'esc' to close
-Space    Select/deselect row
-y        Copy selected content (either rows, or currently open details) to clipboard
-c        Open 'scontrol' prompt for selected items, or current row if no selection (opens prompt)
-Enter    Show details for selected row
-Esc      Close modal
-
-ADDITIONAL SHORTCUTS IN JOBS VIEW (SCONTROL)
-Ctrl+D   Open 'scancel' prompt for selected jobs, or current row if no selection
-
-ADDITIONAL SHORTCUTS IN ACCOUNTING MANAGER VIEW (SACCTMGR)
-e        Focus on Entity type selector, 'esc' to close
-`
+	STUI_VERSION = "0.9.0"
 
 	// Below columns list fetched from Slurm 24.11.3, and are the defaults output by `scontrol` with `--details`
 	ALL_OTHER_JOB_COLUMNS  = "JobName,UserId,GroupId,MCS_label,Priority,Nice,Account,QOS,WCKey,Reason,Dependency,Requeue,Restarts,BatchFlag,Reboot,ExitCode,DerivedExitCode,RunTime,TimeLimit,TimeMin,SubmitTime,EligibleTime,AccrueTime,StartTime,EndTime,Deadline,SuspendTime,SecsPreSuspend,LastSchedEval,Scheduler,AllocNode:Sid,ReqNodeList,ExcNodeList,NodeList,NumNodes,NumCPUs,NumTasks,CPUs/Task,ReqB:S:C:T,ReqTRES,AllocTRES,Socks/Node,NtasksPerN:B:S:C,CoreSpec,MinCPUsNode,MinMemoryNode,MinTmpDiskNode,Features,DelayBoot,OverSubscribe,Contiguous,Licenses,Network,Command,WorkDir,StdErr,StdIn,StdOut,TresPerTask"
@@ -98,6 +109,11 @@ e        Focus on Entity type selector, 'esc' to close
 	// The column below is a subset that excludes the fields that are always shown.
 	ALL_OTHER_SACCT_COLUMNS = "AdminComment,AllocNodes,AssocID,AveCPU,AveCPUFreq,AveDiskRead,AveDiskWrite,AvePages,AveRSS,AveVMSize,BlockID,CPUTime,CPUTimeRAW,Cluster,Constraints,ConsumedEnergy,ConsumedEnergyRaw,Container,DBIndex,DerivedExitCode,ElapsedRaw,Eligible,End,Extra,FailedNode,Flags,GID,Group,JobID,Layout,Licenses,MaxDiskRead,MaxDiskReadNode,MaxDiskReadTask,MaxDiskWrite,MaxDiskWriteNode,MaxDiskWriteTask,MaxPages,MaxPagesNode,MaxPagesTask,MaxRSS,MaxRSSNode,MaxRSSTask,MaxVMSize,MaxVMSizeNode,MaxVMSizeTask,McsLabel,MinCPU,MinCPUNode,MinCPUTask,NCPUS,NNodes,NTasks,Planned,PlannedCPU,PlannedCPURAW,Priority,QOSRAW,Reason,ReqCPUFreq,ReqCPUFreqGov,ReqCPUFreqMax,ReqCPUFreqMin,ReqNodes,Reservation,ReservationId,Start,Submit,Suspended,SystemCPU,SystemComment,TRESUsageInAve,TRESUsageInMax,TRESUsageInMaxNode,TRESUsageInMaxTask,TRESUsageInMin,TRESUsageInMinNode,TRESUsageInMinTask,TRESUsageInTot,TRESUsageOutAve,TRESUsageOutMax,TRESUsageOutMaxNode,TRESUsageOutMaxTask,TRESUsageOutMin,TRESUsageOutMinNode,TRESUsageOutMinTask,TRESUsageOutTot,Timelimit,TimelimitRaw,TotalCPU,UID,UserCPU,WCKey,WCKeyID,WorkDir"
 
+	// Columns that are always shown first in each view, see SetNodeViewColumns etc.
+	FIXED_NODE_VIEW_COLUMNS  = "NodeName,Partitions,State"
+	FIXED_JOB_VIEW_COLUMNS   = "JobId,Partition,JobState"
+	FIXED_SACCT_VIEW_COLUMNS = "JobIDRaw,Partition,State"
+
 	// Certain config option names are specified as vars since they are used in other places
 	CONFIG_OPTION_NAME_LOAD_SACCT_DATA_FROM = "load-sacct-data-from"
 
@@ -107,14 +123,132 @@ e        Focus on Entity type selector, 'esc' to close
 	LOG_LEVEL_INFO  = 2
 	LOG_LEVEL_DEBUG = 3
 
+	// Backends
+	BACKEND_CLI        = "cli"
+	BACKEND_SLURMRESTD = "slurmrestd"
+
+	// Federated view, see the 'clusters' flag
+	CLUSTERS_ALL   = "all"
+	CLUSTER_COLUMN = "Cluster"
+
+	// Clipboard backends, see the 'clipboard' flag
+	CLIPBOARD_AUTO      = "auto"
+	CLIPBOARD_X11       = "x11"
+	CLIPBOARD_OSC52     = "osc52"
+	CLIPBOARD_FILE      = "file"
+	CLIPBOARD_FILE_NAME = "clipboard.txt" // Written to the config dir with the 'file' backend
+
+	// Audit log of commands run from the UI, see the 'audit-log' flag
+	AUDIT_LOG_FILE_NAME = "audit.jsonl"
+
+	// Subcommands
+	EXPORT_COMMAND = "export"
+
 	// Misc
 	ALL_CATEGORIES_OPTION   = "(all)"
 	NO_SORT_OPTION          = "(no sort)"
 	DEFAULT_CONFIG_LOCATION = "/home/$USER/.config/stui.d/"
+	SYSTEM_CONFIG_LOCATION  = "/etc/stui.d/"
 )
 
 func Configure() {
-	// Config flags
+	defineConfigFlags()
+
+	// One-shot-and-exit flags
+	versionFlag := flag.Bool("version", false, "print version information and exit")
+	keyboardShortcutsFlag := flag.Bool("show-keyboard-shortcuts", false, "print keyboard shortcuts, with keybindings from config files, and exit")
+
+	// 'stui export' takes the same flags as the UI, plus flags to choose what to export
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == EXPORT_COMMAND {
+		ExportMode = true
+		args = args[1:]
+		flag.StringVar(&ExportView, "view", ExportView, "view to export, one of 'nodes', 'jobs', 'sacct' or 'sacctmgr:<entity>', e.g. 'sacctmgr:QOS'")
+		flag.StringVar(&ExportFormat, "format", ExportFormat, "output format, one of 'csv', 'json', 'tsv', 'markdown' or 'text'")
+		flag.StringVar(&ExportState, "state", ExportState, "only export rows in this state, like the state selector, leave empty to export all states")
+		flag.StringVar(&ExportSearch, "search", ExportSearch, "only export rows matching this regex, like the search bar, leave empty to export all rows")
+	}
+	flag.CommandLine.Parse(args)
+	flag.Visit(func(f *flag.Flag) {
+		commandLineFlags[f.Name] = true
+	})
+	saveSlurmConfEnv()
+
+	// Handle one shot commands
+	if *versionFlag {
+		fmt.Printf("stui version %s\n", STUI_VERSION)
+		os.Exit(0)
+	}
+	// Load config files if they exist, system-wide defaults first, so that personal config files
+	// take precedence. Settings from config files apply only to flags not given on the command line.
+	if ConfigDirPath == DEFAULT_CONFIG_LOCATION {
+		user, err := user.Current()
+		if err != nil {
+			log.Fatalf("Could not determine current user: %v", err)
+		}
+		ConfigDirPath = fmt.Sprintf(
+			"%s/.config/stui.d/",
+			user.HomeDir,
+		)
+	}
+	configDirs := []string{SYSTEM_CONFIG_LOCATION}
+	if filepath.Clean(ConfigDirPath) != filepath.Clean(SYSTEM_CONFIG_LOCATION) {
+		configDirs = append(configDirs, ConfigDirPath)
+	}
+	loadedConfig = loadConfigDirs(configDirs)
+	if err := applySettings(loadedConfig.Settings, false); err != nil {
+		log.Fatalf("Invalid config file settings: %v", err)
+	}
+	if err := applyKeybindings(loadedConfig.Keybindings); err != nil {
+		log.Fatalf("Invalid config file keybindings: %v", err)
+	}
+
+	// Profile settings take precedence over other settings from config files, but not over flags
+	saveStartupFlagValues()
+	if err := applyProfile(ActiveProfile, false); err != nil {
+		log.Fatalf("Invalid arguments: %v", err)
+	}
+
+	// Shortcuts are printed once config files are loaded, to show the keybindings of the user
+	if *keyboardShortcutsFlag {
+		if err := validateKeybindings(ConfigFile.Plugins); err != nil {
+			log.Fatalf("Invalid keybindings: %v", err)
+		}
+		fmt.Print(KeyboardShortcutsHelp())
+		os.Exit(0)
+	}
+
+	if RecordDir != "" && ReplayDir != "" {
+		log.Fatalf("Invalid arguments: 'record' and 'replay' cannot be used together")
+	}
+	if RecordDir != "" {
+		if err := recorder.EnableRecording(RecordDir); err != nil {
+			log.Fatalf("Invalid arguments: %v", err)
+		}
+	}
+	if ReplayDir != "" {
+		if err := recorder.EnableReplay(ReplayDir); err != nil {
+			log.Fatalf("Invalid arguments: failed to load recordings: %v", err)
+		}
+	}
+
+	// Commands are only run from the UI
+	if !ExportMode {
+		if AuditLog == "" {
+			AuditLog = filepath.Join(ConfigDirPath, AUDIT_LOG_FILE_NAME)
+		}
+		if err := audit.Enable(AuditLog); err != nil {
+			log.Fatalf("Invalid arguments: %v", err)
+		}
+	}
+
+	if err := connectToCluster(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// defineConfigFlags defines the flags that can also be set from config files
+func defineConfigFlags() {
 	flag.DurationVar(&RefreshInterval, "refresh-interval", RefreshInterval, "interval when to refetch data, specify as a duration e.g. '300ms', '1s', '2m'")
 	flag.DurationVar(&RequestTimeout, "request-timeout", RequestTimeout, "timeout setting for fetching data, specify as a duration e.g. '300ms', '1s', '2m'")
 	flag.StringVar(&SlurmBinariesPath, "slurm-binaries-path", SlurmBinariesPath, "path where Slurm binaries like 'sinfo' and 'squeue' can be found, if not in $PATH")
@@ -122,131 +256,205 @@ func Configure() {
 	flag.StringVar(&rawNodeViewColumns, "node-columns-config", rawNodeViewColumns, "comma-separated list of scontrol fields to show in node view, use '//' to combine column or '++' to extend columns to full width. 'NodeName', 'Partition' and 'State' are always shown.")
 	flag.StringVar(&rawJobViewColumns, "job-columns-config", rawJobViewColumns, "comma-separated list of scontrol fields to show in job view, use '//' to combine column or '++' to extend columns to full width. 'JobId', 'Partitions' and 'JobState' are always shown.")
 	flag.StringVar(&rawSacctViewColumns, "sacct-columns-config", rawSacctViewColumns, "comma-separated list of sacct fields to show in job view, use '//' to combine columns or '++' to extend columns to full width. 'JobIDRaw', 'Partitions' and 'State' are always shown.")
+	flag.StringVar(&rawSstatViewColumns, "sstat-columns-config", rawSstatViewColumns, "comma-separated list of sstat fields to show in job usage view, use '//' to combine columns or '++' to extend columns to full width. 'JobID' is always shown.")
+	flag.StringVar(&Clusters, "clusters", Clusters, "query several clusters of a federation like 'scontrol -M', either 'all' or a comma-separated list, and show them in one view with a 'Cluster' column. Leave empty to query the local cluster only")
 	flag.StringVar(&PartitionFilter, "partition", PartitionFilter, "limit views to specific partition only, leave empty to show all partitions")
 	flag.StringVar(&ConfigDirPath, "config-dir", ConfigDirPath, "path to a directory with config files")
+	flag.StringVar(&ActiveProfile, "profile", ActiveProfile, "name of the cluster profile from config files to start with, leave empty to use settings outside of profiles")
 	flag.BoolVar(&CopyFirstColumnOnly, "copy-first-column-only", CopyFirstColumnOnly, "if true, only copy the first column of the table to clipboard when copying")
 	flag.BoolVar(&ShowAllColumns, "show-all-columns", ShowAllColumns, "if set, shows all columns for Nodes, Jobs and Accounting view Jobs, overriding other specific config")
 	flag.IntVar(&LogLevel, "log-level", LogLevel, "log level, 0=none, 1=error, 2=info, 3=debug")
+	flag.StringVar(&Clipboard, "clipboard", Clipboard, "how to copy to clipboard, one of 'x11' (xclip, xsel or wl-copy), 'osc52' (terminal escape sequence, works over SSH), 'file' (write to 'clipboard.txt' in the config dir) or 'auto' to use 'x11' if there is a display, 'osc52' in SSH sessions, and 'file' otherwise")
 	flag.StringVar(&CopiedLinesSeparator, "copied-lines-separator", CopiedLinesSeparator, "string to use when separating copied lines in clipboard")
 	flag.DurationVar(&LoadSacctDataFrom, CONFIG_OPTION_NAME_LOAD_SACCT_DATA_FROM, LoadSacctDataFrom, "load sacct data starting from this long ago, specify as a duration, e.g. '1h', '2h'. This can be very slow on busy clusters, so use with caution. Set to 0 to not load any data from sacct.")
+	flag.StringVar(&SacctUsers, "sacct-users", SacctUsers, "comma-separated list of users to load sacct data for, leave empty to load data for all users")
+	flag.StringVar(&SacctAccounts, "sacct-accounts", SacctAccounts, "comma-separated list of accounts to load sacct data for, leave empty to load data for all accounts")
+	flag.StringVar(&SacctStates, "sacct-states", SacctStates, "comma-separated list of job states to load sacct data for, e.g. 'FAILED,TIMEOUT', leave empty to load all states")
+	flag.StringVar(&Backend, "backend", Backend, "where to fetch data from, either 'cli' to run Slurm binaries, or 'slurmrestd' to use the Slurm REST API")
+	flag.StringVar(&SlurmRestdURL, "slurmrestd-url", SlurmRestdURL, "base URL of slurmrestd, e.g. 'http://localhost:6820', required if backend is 'slurmrestd'")
+	flag.StringVar(&SlurmRestdAPIVersion, "slurmrestd-api-version", SlurmRestdAPIVersion, "slurmrestd API version to use, e.g. 'v0.0.40', 'v0.0.41'")
+	flag.StringVar(&SlurmRestdTokenFile, "slurmrestd-token-file", SlurmRestdTokenFile, "path to a file containing a JWT for slurmrestd, if not set, fall back to SLURM_JWT env var")
+	flag.StringVar(&RecordDir, "record", RecordDir, "record raw outputs of all Slurm commands into this directory, e.g. to attach to a bug report")
+	flag.StringVar(&ReplayDir, "replay", ReplayDir, "replay outputs recorded with '-record' from this directory instead of querying Slurm, stepping through snapshots on each refresh")
+	flag.StringVar(&AuditLog, "audit-log", AuditLog, "append-only JSON-lines file recording every command run from stui with its user, cluster, targets, exit code and output hash, leave empty to write 'audit.jsonl' in the config dir")
 
 	// Config flags that have been deprecated from user config
 	// flag.DurationVar(&SearchDebounceInterval, "search-debounce-interval", SearchDebounceInterval, "interval to wait before searching, specify as a duration e.g. '300ms', '1s', '2m'")
+}
 
-	// Load config file if it exists
-	if ConfigDirPath == DEFAULT_CONFIG_LOCATION {
-		user, err := user.Current()
-		if err != nil {
-			log.Fatalf("Could not determine current user: %v", err)
+// loadConfigDirs merges the config files of the directories that exist, later ones taking precedence
+func loadConfigDirs(configDirs []string) Config {
+	merged := NewConfig()
+	for _, path := range configDirs {
+		if _, err := os.Stat(path); err != nil {
+			// No need to print a message as configuration files are NOT mandatory.
+			continue
 		}
-		ConfigDirPath = fmt.Sprintf(
-			"%s/.config/stui.d/",
-			user.HomeDir,
-		)
-	}
-	if _, err := os.Stat(ConfigDirPath); err != nil {
-		// No need to print a message as configuration file is NOT mandatory.
-	} else {
-		ConfigFile = LoadConfigsFromDir(ConfigDirPath)
-	}
-
-	// One-shot-and-exit flags
-	versionFlag := flag.Bool("version", false, "print version information and exit")
-	keyboardShortcutsFlag := flag.Bool("show-keyboard-shortcuts", false, "print keyboard shortcuts and exit")
-
-	flag.Parse()
-
-	// Handle one shot commands
-	if *versionFlag {
-		fmt.Printf("stui version %s\n", STUI_VERSION)
-		os.Exit(0)
-	}
-	if *keyboardShortcutsFlag {
-		fmt.Print(KEYBOARD_SHORTCUTS)
-		os.Exit(0)
+		merged = mergeConfigs(merged, LoadConfigsFromDir(path))
 	}
+	return merged
+}
 
+// connectToCluster validates the cluster-specific settings, computes derived configs and
+// fetches scheduler info. Used at startup, and again when switching profiles.
+func connectToCluster() error {
 	// If slurm.conf location was given, ensure file exists and configure env var if appropriate
 	if SlurmConfLocation != "" {
 		if _, err := os.Stat(SlurmConfLocation); err != nil {
-			log.Fatalf("Specified Slurm conf file cannot be found: %v", err)
+			return fmt.Errorf("Specified Slurm conf file cannot be found: %v", err)
 		}
 		err := os.Setenv("SLURM_CONF", SlurmConfLocation)
 		if err != nil {
-			log.Fatalf("Failed to set SLURM_CONF environment variable: %v", err)
+			return fmt.Errorf("Failed to set SLURM_CONF environment variable: %v", err)
 		}
+	} else {
+		restoreSlurmConfEnv()
 	}
 
 	// Validate input and configs
 	if RequestTimeout > RefreshInterval {
-		log.Fatalf("Invalid arguments: request timeout of '%d' is longer than refresh interval of '%d'", RequestTimeout, RefreshInterval)
+		return fmt.Errorf("Invalid arguments: request timeout of '%d' is longer than refresh interval of '%d'", RequestTimeout, RefreshInterval)
+	}
+
+	switch Backend {
+	case BACKEND_CLI:
+	case BACKEND_SLURMRESTD:
+		if err := configureSlurmRestd(); err != nil {
+			return fmt.Errorf("Invalid slurmrestd configuration: %v", err)
+		}
+	default:
+		return fmt.Errorf("Invalid arguments: unknown backend '%s', must be one of '%s' or '%s'", Backend, BACKEND_CLI, BACKEND_SLURMRESTD)
+	}
+	if !slices.Contains([]string{CLIPBOARD_AUTO, CLIPBOARD_X11, CLIPBOARD_OSC52, CLIPBOARD_FILE}, Clipboard) {
+		return fmt.Errorf("Invalid arguments: unknown clipboard '%s', must be one of '%s', '%s', '%s' or '%s'", Clipboard, CLIPBOARD_AUTO, CLIPBOARD_X11, CLIPBOARD_OSC52, CLIPBOARD_FILE)
+	}
+	if Clusters != "" && Backend != BACKEND_CLI {
+		return fmt.Errorf("Invalid arguments: 'clusters' is only supported with the '%s' backend", BACKEND_CLI)
+	}
+	if err := validateKeybindings(ConfigFile.Plugins); err != nil {
+		return fmt.Errorf("Invalid keybindings: %v", err)
 	}
 
 	ComputeConfigurations()
 
 	if err := checkIfClusterIsReachable(); err != nil {
-		log.Fatalf("Failed to connect to Slurm: %v", err)
+		return fmt.Errorf("Failed to connect to Slurm: %v", err)
 	}
 
 	// Get scheduler info
 	SchedulerHostName, ClusterName, SchedulerSlurmVersion = getSchedulerInfoWithTimeout(RequestTimeout)
 
 	checkIfSacctMgrIsAvailable()
+	return nil
 }
 
-func ComputeConfigurations() {
-	// Parse raw config entries
-	var err error
-	// Add hardcoded fields to Node
-	// NodeName must be first column, as it is unique and used for selections
-	// Partitions and State are used as filters and must be included.
-	if ShowAllColumns {
-		rawNodeViewColumns = fmt.Sprintf("NodeName,Partitions,State,%s", ALL_OTHER_NODE_COLUMNS) // Must have NodeName for selection to work!
-	} else {
-		rawNodeViewColumns = fmt.Sprintf("NodeName,Partitions,State,%s", rawNodeViewColumns) // Must have NodeName for selection to work!
+// JSONOutputUnavailable returns true if JSON output failed as unsupported on the given cluster
+func JSONOutputUnavailable(cluster string) bool {
+	jsonOutputUnavailableMu.Lock()
+	defer jsonOutputUnavailableMu.Unlock()
+	return jsonOutputUnavailable[cluster]
+}
+
+// DisableJSONOutput makes all further fetches from the given cluster use the text output,
+// returning false if it was already disabled
+func DisableJSONOutput(cluster string) bool {
+	jsonOutputUnavailableMu.Lock()
+	defer jsonOutputUnavailableMu.Unlock()
+	if jsonOutputUnavailable[cluster] {
+		return false
 	}
-	NodeViewColumns, err = parseColumnConfigLine(rawNodeViewColumns)
+	jsonOutputUnavailable[cluster] = true
+	return true
+}
+
+// ResetJSONOutput tries JSON output again on all clusters
+func ResetJSONOutput() {
+	jsonOutputUnavailableMu.Lock()
+	defer jsonOutputUnavailableMu.Unlock()
+	jsonOutputUnavailable = map[string]bool{}
+}
+
+// SetNodeViewColumns parses the node view column config, and adds the fixed columns in front.
+// NodeName must be first column, as it is unique and used for selections.
+// Partitions and State are used as filters and must be included.
+func SetNodeViewColumns(rawColumns string) error {
+	columns, err := parseColumnConfigLine(withFixedColumns(FIXED_NODE_VIEW_COLUMNS, rawColumns))
 	if err != nil {
-		log.Fatalf("Failed to parse node column config: %v", err)
+		return err
 	}
+	NodeViewColumns = columns
 	NodeViewColumnsPartitionIndex = GetColumnIndexFromColumnConfig(NodeViewColumns, "Partitions")
 	NodeViewColumnsStateIndex = GetColumnIndexFromColumnConfig(NodeViewColumns, "State")
+	return nil
+}
 
-	// Add hardcoded fields to Job columns
-	// JobID must be first column, as it is unique and used for selections
-	// Partition and JobState are used as filters and must be included.
-	// If all columns are requested, override list here.
-	if ShowAllColumns {
-		rawJobViewColumns = fmt.Sprintf("JobId,Partition,JobState,%s", ALL_OTHER_JOB_COLUMNS)
-	} else {
-		rawJobViewColumns = fmt.Sprintf("JobId,Partition,JobState,%s", rawJobViewColumns) // Must have JobID for selection to work!
-	}
-	JobViewColumns, err = parseColumnConfigLine(rawJobViewColumns)
+// SetJobViewColumns parses the job view column config, and adds the fixed columns in front.
+// JobId must be first column, as it is unique and used for selections.
+// Partition and JobState are used as filters and must be included.
+func SetJobViewColumns(rawColumns string) error {
+	columns, err := parseColumnConfigLine(withFixedColumns(FIXED_JOB_VIEW_COLUMNS, rawColumns))
 	if err != nil {
-		log.Fatalf("Failed to parse job column config: %v", err)
+		return err
 	}
+	JobViewColumns = columns
 	JobsViewColumnsPartitionIndex = GetColumnIndexFromColumnConfig(JobViewColumns, "Partition")
 	JobsViewColumnsStateIndex = GetColumnIndexFromColumnConfig(JobViewColumns, "JobState")
+	return nil
+}
 
-	// Sacct view
-	// JobID must be first column, as it is unique and used for selections
-	// Partition and State are used as filters and must be included.
-	// If all columns are requested, override list here.
+// SetSacctViewColumns parses the sacct view column config, and adds the fixed columns in front.
+// JobIDRaw must be first column, as it is unique and used for selections.
+// Partition and State are used as filters and must be included.
+func SetSacctViewColumns(rawColumns string) error {
+	columns, err := parseColumnConfigLine(withFixedColumns(FIXED_SACCT_VIEW_COLUMNS, rawColumns))
+	if err != nil {
+		return err
+	}
+	SacctViewColumns = columns
+	SacctViewColumnsPartitionIndex = GetColumnIndexFromColumnConfig(SacctViewColumns, "Partition")
+	SacctViewColumnsStateIndex = GetColumnIndexFromColumnConfig(SacctViewColumns, "State")
+	return nil
+}
+
+func ComputeConfigurations() {
+	// Parse raw config entries
+	var err error
+	if ShowAllColumns {
+		rawNodeViewColumns = ALL_OTHER_NODE_COLUMNS
+		rawJobViewColumns = ALL_OTHER_JOB_COLUMNS
+	}
+	if Clusters != "" {
+		// Rows from all clusters are shown together, so show where each is from
+		rawNodeViewColumns = withClusterColumn(rawNodeViewColumns)
+		rawJobViewColumns = withClusterColumn(rawJobViewColumns)
+		rawSacctViewColumns = withClusterColumn(rawSacctViewColumns)
+	}
+	AvailableNodeViewColumns = availableColumns(rawNodeViewColumns, ALL_OTHER_NODE_COLUMNS)
+	AvailableJobViewColumns = availableColumns(rawJobViewColumns, ALL_OTHER_JOB_COLUMNS)
+	AvailableSacctViewColumns = availableColumns(rawSacctViewColumns, ALL_OTHER_SACCT_COLUMNS)
+
+	if err = SetNodeViewColumns(rawNodeViewColumns); err != nil {
+		log.Fatalf("Failed to parse node column config: %v", err)
+	}
+	if err = SetJobViewColumns(rawJobViewColumns); err != nil {
+		log.Fatalf("Failed to parse job column config: %v", err)
+	}
 
 	// Since we need all the columns anyway for use in sacct detail view, set it here
-	AllSacctViewColumns = fmt.Sprintf("JobIDRaw,Partition,State,%s,%s", rawSacctViewColumns, ALL_OTHER_SACCT_COLUMNS)
+	AllSacctViewColumns = fmt.Sprintf("%s,%s,%s", FIXED_SACCT_VIEW_COLUMNS, rawSacctViewColumns, ALL_OTHER_SACCT_COLUMNS)
 	if ShowAllColumns {
-		rawSacctViewColumns = AllSacctViewColumns
-	} else {
-		rawSacctViewColumns = fmt.Sprintf("JobIDRaw,Partition,State,%s", rawSacctViewColumns)
+		rawSacctViewColumns = fmt.Sprintf("%s,%s", rawSacctViewColumns, ALL_OTHER_SACCT_COLUMNS)
+	}
+	if err = SetSacctViewColumns(rawSacctViewColumns); err != nil {
+		log.Fatalf("Failed to parse sacct column config: %v", err)
 	}
 
-	SacctViewColumns, err = parseColumnConfigLine(rawSacctViewColumns)
+	// Sstat view, showing one row per job step.
+	// JobID must be first column, as it identifies the step.
+	SstatViewColumns, err = parseColumnConfigLine(fmt.Sprintf("JobID,%s", rawSstatViewColumns))
 	if err != nil {
-		log.Fatalf("Failed to parse sacct column config: %v", err)
+		log.Fatalf("Failed to parse sstat column config: %v", err)
 	}
-	SacctViewColumnsPartitionIndex = GetColumnIndexFromColumnConfig(SacctViewColumns, "Partition")
-	SacctViewColumnsStateIndex = GetColumnIndexFromColumnConfig(SacctViewColumns, "State")
 
 	// It is easier for us to manage rendering and coloring if `State` is always in the same place.
 	// These values are effectively hardcoded, so checking this condition here is safe.
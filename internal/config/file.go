@@ -1,9 +1,16 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/stretchr/testify/assert/yaml"
 )
@@ -15,12 +22,61 @@ type PluginConfig struct {
 	Command                 string `yaml:"command"`
 	ExecuteImmediately      bool   `yaml:"executeImmediately"`
 	ClosePromptAfterExecute bool   `yaml:"closePromptAfterExecute"`
+	Selection               string `yaml:"selection"`   // One of the PLUGIN_SELECTION_* consts, 'single' by default
+	IdSeparator             string `yaml:"idSeparator"` // Separator of the ids in '.Ids', ',' by default
+
+	// Only offer the plugin for rows matching this condition, e.g. 'JobState == "RUNNING"'
+	When string `yaml:"when"`
+
+	// Ask before running the command, even if 'executeImmediately' is set
+	Confirm bool `yaml:"confirm"`
+
+	// Run the command in the background without the command prompt, listed in the tasks view
+	Background bool `yaml:"background"`
+
+	// How the command is run, there is no timeout by default. Timeouts need a unit, e.g. '30s'.
+	Timeout time.Duration     `yaml:"timeout"`
+	Env     map[string]string `yaml:"env"`
+	Workdir string            `yaml:"workdir"`
 }
 
+// Rows a plugin command is run for. With 'multiple' and 'each', the selected rows are used,
+// or the row under the cursor if there is no selection.
+const (
+	PLUGIN_SELECTION_SINGLE   = "single"   // Run once for the row under the cursor
+	PLUGIN_SELECTION_MULTIPLE = "multiple" // Run once, with all rows in '.Rows' and '.Ids'
+	PLUGIN_SELECTION_EACH     = "each"     // Run once for each row until one fails, showing all outputs together
+
+	DEFAULT_PLUGIN_ID_SEPARATOR = ","
+)
+
+// Shorter timeouts are most likely mistakes, e.g. '30ms' instead of '30s'
+const PLUGIN_MIN_TIMEOUT = time.Second
+
+var PLUGIN_SELECTIONS = []string{PLUGIN_SELECTION_SINGLE, PLUGIN_SELECTION_MULTIPLE, PLUGIN_SELECTION_EACH}
+
 type Config struct {
 	Plugins []PluginConfig `yaml:"plugins"`
+
+	// Settings map the name of any command line flag to its value, e.g. 'refresh-interval: 5s'
+	Settings map[string]any `yaml:"settings"`
+
+	// Profiles are named sets of settings and plugins, e.g. one for each cluster
+	Profiles []ProfileConfig `yaml:"profiles"`
+
+	// Keybindings map the name of an action to a key or a list of keys, e.g. 'sort: S'
+	Keybindings map[string]any `yaml:"keybindings"`
 }
 
+// Top-level keys accepted in config files
+var CONFIG_FILE_KEYS = []string{"plugins", "settings", "profiles", "keybindings"}
+
+// Flags that cannot be set in config files, as they are needed before config files are read
+var SETTINGS_NOT_ALLOWED_IN_CONFIG_FILES = []string{"config-dir", "version", "show-keyboard-shortcuts"}
+
+// Flags given on the command line, which take precedence over config files
+var commandLineFlags = map[string]bool{}
+
 func LoadConfigsFromDir(path string) Config {
 	files, err := os.ReadDir(path)
 	if err != nil {
@@ -45,27 +101,189 @@ func loadConfig(path string) Config {
 		log.Fatalf("failed to read config file '%s': %v", path, err)
 	}
 
+	// Checked first, as the error when parsing the timeout doesn't name the plugin
+	if err = validatePluginTimeoutUnits(data); err != nil {
+		log.Fatalf("invalid config file '%s': %v", path, err)
+	}
 	config := NewConfig()
 	err = yaml.Unmarshal(data, &config)
 	if err != nil {
 		log.Fatalf("failed to parse YAML from config file '%s': %v", path, err)
 	}
+	if err = validateConfigKeys(data, config); err != nil {
+		log.Fatalf("invalid config file '%s': %v", path, err)
+	}
 
 	return config
 }
 
+// validateConfigKeys checks that the config file only has known top-level keys,
+// and that each setting, including those of profiles, is a flag that can be set from a config file.
+func validateConfigKeys(data []byte, config Config) error {
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for key := range raw {
+		if !slices.Contains(CONFIG_FILE_KEYS, key) {
+			return fmt.Errorf("unknown key '%s', must be one of '%s'", key, strings.Join(CONFIG_FILE_KEYS, "', '"))
+		}
+	}
+
+	if err := validateSettings(config.Settings); err != nil {
+		return err
+	}
+	if err := validatePlugins(config.Plugins); err != nil {
+		return err
+	}
+	for _, profile := range config.Profiles {
+		if profile.Name == "" {
+			return errors.New("profiles must have a name")
+		}
+		if _, found := profile.Settings["profile"]; found {
+			return fmt.Errorf("profile '%s' cannot set 'profile'", profile.Name)
+		}
+		if err := validateSettings(profile.Settings); err != nil {
+			return fmt.Errorf("profile '%s': %v", profile.Name, err)
+		}
+		if err := validatePlugins(profile.Plugins); err != nil {
+			return fmt.Errorf("profile '%s': %v", profile.Name, err)
+		}
+	}
+	return nil
+}
+
+func validatePlugins(plugins []PluginConfig) error {
+	for _, plugin := range plugins {
+		if plugin.Selection != "" && !slices.Contains(PLUGIN_SELECTIONS, plugin.Selection) {
+			return fmt.Errorf("plugin '%s' has unknown selection '%s', must be one of '%s'", plugin.Name, plugin.Selection, strings.Join(PLUGIN_SELECTIONS, "', '"))
+		}
+		if plugin.Timeout != 0 && plugin.Timeout < PLUGIN_MIN_TIMEOUT {
+			return fmt.Errorf("plugin '%s' has a timeout of %v, must be at least %v", plugin.Name, plugin.Timeout, PLUGIN_MIN_TIMEOUT)
+		}
+	}
+	return nil
+}
+
+// pluginTimeouts are the timeouts of plugins as written in config files
+type pluginTimeouts []struct {
+	Name    string `yaml:"name"`
+	Timeout any    `yaml:"timeout"`
+}
+
+// validatePluginTimeoutUnits checks that plugin timeouts have a unit, as plain numbers like '30'
+// are not durations
+func validatePluginTimeoutUnits(data []byte) error {
+	var raw struct {
+		Plugins  pluginTimeouts `yaml:"plugins"`
+		Profiles []struct {
+			Name    string         `yaml:"name"`
+			Plugins pluginTimeouts `yaml:"plugins"`
+		} `yaml:"profiles"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	check := func(plugins pluginTimeouts) error {
+		for _, plugin := range plugins {
+			if _, isText := plugin.Timeout.(string); plugin.Timeout != nil && !isText {
+				return fmt.Errorf("plugin '%s' has a timeout of '%v' without a unit, use e.g. '%vs' for seconds", plugin.Name, plugin.Timeout, plugin.Timeout)
+			}
+		}
+		return nil
+	}
+	if err := check(raw.Plugins); err != nil {
+		return err
+	}
+	for _, profile := range raw.Profiles {
+		if err := check(profile.Plugins); err != nil {
+			return fmt.Errorf("profile '%s': %v", profile.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateSettings(settings map[string]any) error {
+	for name := range settings {
+		if slices.Contains(SETTINGS_NOT_ALLOWED_IN_CONFIG_FILES, name) {
+			return fmt.Errorf("setting '%s' can only be given as a command line flag", name)
+		}
+		if flag.Lookup(name) == nil {
+			return fmt.Errorf("unknown setting '%s', settings must be named like the command line flags listed in 'stui -help'", name)
+		}
+	}
+	return nil
+}
+
+// applySettings sets flags from config file settings. Flags given on the command line take
+// precedence, unless overrideCommandLine is set. Lists are joined with commas, e.g. for column configs.
+func applySettings(settings map[string]any, overrideCommandLine bool) error {
+	// Apply in a fixed order, so any errors are reported consistently
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if commandLineFlags[name] && !overrideCommandLine {
+			continue
+		}
+		value := settings[name]
+		if list, isList := value.([]any); isList {
+			var values []string
+			for _, item := range list {
+				values = append(values, fmt.Sprint(item))
+			}
+			value = strings.Join(values, ",")
+		}
+		if err := flag.Set(name, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("invalid value '%v' for setting '%s': %v", value, name, err)
+		}
+	}
+	return nil
+}
+
 // Merges two configs, with the nextLayer config taking precedence on specific keys. Arrays
 // are concatenated, and maps are merged.
 // This is a custom implementation and needs updating as the config structure changes.
 func mergeConfigs(base Config, nextLayer Config) Config {
 	merged := Config{
-		Plugins: append(base.Plugins, nextLayer.Plugins...),
+		Plugins:     append(base.Plugins, nextLayer.Plugins...),
+		Settings:    map[string]any{},
+		Profiles:    slices.Clone(base.Profiles),
+		Keybindings: map[string]any{},
+	}
+	maps.Copy(merged.Settings, base.Settings)
+	maps.Copy(merged.Settings, nextLayer.Settings)
+	maps.Copy(merged.Keybindings, base.Keybindings)
+	maps.Copy(merged.Keybindings, nextLayer.Keybindings)
+
+	// Profiles with the same name are merged like configs
+	for _, profile := range nextLayer.Profiles {
+		index := slices.IndexFunc(merged.Profiles, func(p ProfileConfig) bool { return p.Name == profile.Name })
+		if index < 0 {
+			merged.Profiles = append(merged.Profiles, profile)
+			continue
+		}
+		existing := merged.Profiles[index]
+		settings := map[string]any{}
+		maps.Copy(settings, existing.Settings)
+		maps.Copy(settings, profile.Settings)
+		merged.Profiles[index] = ProfileConfig{
+			Name:     profile.Name,
+			Settings: settings,
+			Plugins:  append(slices.Clone(existing.Plugins), profile.Plugins...),
+		}
 	}
 	return merged
 }
 
 func NewConfig() Config {
 	return Config{
-		Plugins: []PluginConfig{},
+		Plugins:     []PluginConfig{},
+		Settings:    map[string]any{},
+		Profiles:    []ProfileConfig{},
+		Keybindings: map[string]any{},
 	}
 }
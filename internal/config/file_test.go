@@ -0,0 +1,178 @@
+package config
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/assert/yaml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// Settings of config files are validated against the flags
+	defineConfigFlags()
+	// Parsed first, so that the flags of the test binary are restored to the given values
+	flag.Parse()
+	saveStartupFlagValues()
+	// The model sets the runner of Slurm commands in the app, which this package can't import
+	RunSlurmCommand = func(binary string, args []string, timeout time.Duration) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return exec.CommandContext(ctx, filepath.Join(SlurmBinariesPath, binary), args...).Output()
+	}
+	os.Exit(m.Run())
+}
+
+// writeConfig writes a config file into a new directory, and returns the directory
+func writeConfig(t *testing.T, content string) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0o644))
+	return dir
+}
+
+// useCommandLineFlags sets flags as if given on the command line, and resets all flags and config
+// loaded by the test once it ends
+func useCommandLineFlags(t *testing.T, flags map[string]string) {
+	defaultFlagValues := startupFlagValues
+	t.Cleanup(func() {
+		startupFlagValues = defaultFlagValues
+		restoreStartupFlagValues()
+		commandLineFlags = map[string]bool{}
+		loadedConfig = Config{}
+		ConfigFile = Config{}
+		Keybindings = defaultKeybindings()
+	})
+	for name, value := range flags {
+		require.NoError(t, flag.Set(name, value))
+		commandLineFlags[name] = true
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	systemDir := writeConfig(t, `
+settings:
+  refresh-interval: 10s
+  request-timeout: 3s
+  partition: system
+  sacct-states: [FAILED, TIMEOUT]
+keybindings:
+  sort: S
+  then-sort: T
+plugins:
+  - name: system plugin
+    activePage: jobs
+    shortcut: x
+`)
+	userDir := writeConfig(t, `
+settings:
+  refresh-interval: 20s
+  partition: user
+keybindings:
+  sort: Alt-s
+plugins:
+  - name: user plugin
+    activePage: jobs
+    shortcut: z
+`)
+	useCommandLineFlags(t, map[string]string{"partition": "flag"})
+
+	loaded := loadConfigDirs([]string{systemDir, filepath.Join(t.TempDir(), "missing"), userDir})
+	require.NoError(t, applySettings(loaded.Settings, false))
+	require.NoError(t, applyKeybindings(loaded.Keybindings))
+
+	// Files in the user's config dir take precedence over /etc/stui.d, and flags over both
+	assert.Equal(t, 20*time.Second, RefreshInterval)
+	assert.Equal(t, 3*time.Second, RequestTimeout)
+	assert.Equal(t, "flag", PartitionFilter)
+	assert.Equal(t, "FAILED,TIMEOUT", SacctStates)
+	assert.Equal(t, []string{"Alt-s"}, Keybindings[ACTION_SORT])
+	assert.Equal(t, []string{"T"}, Keybindings[ACTION_THEN_SORT])
+	assert.Len(t, loaded.Plugins, 2)
+	assert.Equal(t, "system plugin", loaded.Plugins[0].Name)
+
+	// Profiles switched to at runtime take precedence over flags
+	require.NoError(t, applySettings(map[string]any{"partition": "profile"}, true))
+	assert.Equal(t, "profile", PartitionFilter)
+}
+
+func TestValidateConfigKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "known keys",
+			content: "settings:\n  refresh-interval: 5s\nkeybindings:\n  sort: S\nprofiles:\n  - name: test\n    settings:\n      partition: debug\n",
+		},
+		{
+			name:    "unknown top-level key",
+			content: "setting:\n  refresh-interval: 5s\n",
+			err:     "unknown key 'setting', must be one of 'plugins', 'settings', 'profiles', 'keybindings'",
+		},
+		{
+			name:    "unknown setting",
+			content: "settings:\n  refresh-rate: 5s\n",
+			err:     "unknown setting 'refresh-rate', settings must be named like the command line flags",
+		},
+		{
+			name:    "setting only allowed as a flag",
+			content: "settings:\n  config-dir: /tmp\n",
+			err:     "setting 'config-dir' can only be given as a command line flag",
+		},
+		{
+			name:    "unknown setting in a profile",
+			content: "profiles:\n  - name: test\n    settings:\n      refresh-rate: 5s\n",
+			err:     "profile 'test': unknown setting 'refresh-rate'",
+		},
+		{
+			name:    "profile without a name",
+			content: "profiles:\n  - settings:\n      partition: debug\n",
+			err:     "profiles must have a name",
+		},
+		{
+			name:    "profile setting the profile",
+			content: "profiles:\n  - name: test\n    settings:\n      profile: other\n",
+			err:     "profile 'test' cannot set 'profile'",
+		},
+		{
+			name:    "plugin timeout below the minimum",
+			content: "plugins:\n  - name: logs\n    timeout: 30ms\n",
+			err:     "plugin 'logs' has a timeout of 30ms, must be at least 1s",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := NewConfig()
+			require.NoError(t, yaml.Unmarshal([]byte(test.content), &config))
+			err := validateConfigKeys([]byte(test.content), config)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestApplySettingsRejectsInvalidValues(t *testing.T) {
+	useCommandLineFlags(t, nil)
+	err := applySettings(map[string]any{"refresh-interval": "often"}, false)
+	assert.ErrorContains(t, err, "invalid value 'often' for setting 'refresh-interval'")
+}
+
+func TestValidatePluginTimeoutUnits(t *testing.T) {
+	assert.NoError(t, validatePluginTimeoutUnits([]byte("plugins:\n  - name: logs\n    timeout: 30s\n  - name: top\n")))
+
+	err := validatePluginTimeoutUnits([]byte("plugins:\n  - name: logs\n    timeout: 30\n"))
+	assert.EqualError(t, err, "plugin 'logs' has a timeout of '30' without a unit, use e.g. '30s' for seconds")
+
+	err = validatePluginTimeoutUnits([]byte("profiles:\n  - name: test\n    plugins:\n      - name: logs\n        timeout: 30\n"))
+	assert.ErrorContains(t, err, "profile 'test': plugin 'logs' has a timeout of '30' without a unit")
+}
@@ -0,0 +1,381 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// KeybindingAction is an action of the UI that can be bound to keys in the 'keybindings'
+// section of config files, e.g. 'sort: S' or 'move-down: [j, Ctrl-N]'
+type KeybindingAction struct {
+	Name        string
+	Scope       string   // Where the action is available, one of the KEYBINDING_SCOPE_* consts
+	DefaultKeys []string // Keys are single characters, 'Space', or tcell key names like 'Ctrl-D' and 'F1'
+	Description string
+}
+
+// Keybinding scopes. Table actions are available in all table views, the other scopes are named
+// after the page they are available on.
+const (
+	KEYBINDING_SCOPE_GLOBAL   = "global"
+	KEYBINDING_SCOPE_TABLE    = "table"
+	KEYBINDING_SCOPE_JOBS     = "jobs"
+	KEYBINDING_SCOPE_SACCT    = "sacct"
+	KEYBINDING_SCOPE_SACCTMGR = "sacctmgr"
+	KEYBINDING_SCOPE_TASKS    = "tasks"
+
+	KEY_SPACE = "Space" // Name of the space bar, which has no tcell key name
+)
+
+// Actions that can be bound to keys, referenced by the UI
+const (
+	ACTION_QUIT           = "quit"
+	ACTION_HELP           = "help"
+	ACTION_NODES_VIEW     = "nodes-view"
+	ACTION_JOBS_VIEW      = "jobs-view"
+	ACTION_SACCT_VIEW     = "sacct-view"
+	ACTION_SACCTMGR_VIEW  = "sacctmgr-view"
+	ACTION_SCHEDULER_VIEW = "scheduler-view"
+	ACTION_TASKS_VIEW     = "tasks-view"
+	ACTION_PROFILES       = "profiles"
+	ACTION_MOVE_UP        = "move-up"
+	ACTION_MOVE_DOWN      = "move-down"
+	ACTION_MOVE_LEFT      = "move-left"
+	ACTION_MOVE_RIGHT     = "move-right"
+	ACTION_MOVE_TOP       = "move-top"
+	ACTION_MOVE_BOTTOM    = "move-bottom"
+	ACTION_REFRESH        = "refresh"
+	ACTION_SORT           = "sort"
+	ACTION_THEN_SORT      = "then-sort"
+	ACTION_COLUMNS        = "columns"
+	ACTION_SEARCH         = "search"
+	ACTION_PARTITION      = "partition"
+	ACTION_STATE          = "state"
+	ACTION_SELECT         = "select"
+	ACTION_COPY           = "copy"
+	ACTION_EXPORT         = "export"
+	ACTION_COMMAND        = "command"
+	ACTION_DETAILS        = "details"
+	ACTION_CANCEL_JOB     = "cancel-job"
+	ACTION_JOB_USAGE      = "job-usage"
+	ACTION_TIME_RANGE     = "time-range"
+	ACTION_SACCT_FILTERS  = "sacct-filters"
+	ACTION_ENTITY         = "entity"
+	ACTION_RERUN_TASK     = "rerun-task"
+	ACTION_CANCEL_TASK    = "cancel-task"
+)
+
+// KEYBINDING_ACTIONS lists all actions with their default keys, in the order shown in the help
+var KEYBINDING_ACTIONS = []KeybindingAction{
+	{ACTION_NODES_VIEW, KEYBINDING_SCOPE_GLOBAL, []string{"1"}, "Switch to Nodes view (scontrol)"},
+	{ACTION_JOBS_VIEW, KEYBINDING_SCOPE_GLOBAL, []string{"2"}, "Switch to Jobs view (scontrol)"},
+	{ACTION_SACCT_VIEW, KEYBINDING_SCOPE_GLOBAL, []string{"3"}, "Switch to Jobs accounting view (sacct)"},
+	{ACTION_SACCTMGR_VIEW, KEYBINDING_SCOPE_GLOBAL, []string{"4"}, "Switch to Accounting Manager view (sacctmgr)"},
+	{ACTION_SCHEDULER_VIEW, KEYBINDING_SCOPE_GLOBAL, []string{"5"}, "Switch to Scheduler view (sdiag)"},
+	{ACTION_TASKS_VIEW, KEYBINDING_SCOPE_GLOBAL, []string{"6"}, "Switch to Tasks view, listing all commands run in this session"},
+	{ACTION_HELP, KEYBINDING_SCOPE_GLOBAL, []string{"?"}, "Show this help"},
+	{ACTION_PROFILES, KEYBINDING_SCOPE_GLOBAL, []string{"P"}, "Switch cluster profile, as defined in config files"},
+	{ACTION_QUIT, KEYBINDING_SCOPE_GLOBAL, []string{"Ctrl-C"}, "Exit, 'Ctrl-C' always exits, or stops the command running in the command prompt"},
+
+	{ACTION_MOVE_UP, KEYBINDING_SCOPE_TABLE, []string{"k"}, "Move selection up"},
+	{ACTION_MOVE_DOWN, KEYBINDING_SCOPE_TABLE, []string{"j"}, "Move selection down"},
+	{ACTION_MOVE_LEFT, KEYBINDING_SCOPE_TABLE, []string{"h"}, "Scroll left"},
+	{ACTION_MOVE_RIGHT, KEYBINDING_SCOPE_TABLE, []string{"l"}, "Scroll right"},
+	{ACTION_MOVE_TOP, KEYBINDING_SCOPE_TABLE, []string{"g"}, "Move selection to the first row"},
+	{ACTION_MOVE_BOTTOM, KEYBINDING_SCOPE_TABLE, []string{"G"}, "Move selection to the last row"},
+	{ACTION_REFRESH, KEYBINDING_SCOPE_TABLE, []string{"Ctrl-R"}, "Refresh currently visible data"},
+	{ACTION_SORT, KEYBINDING_SCOPE_TABLE, []string{"o"}, "Sort table by column, select the same column again to reverse the direction"},
+	{ACTION_THEN_SORT, KEYBINDING_SCOPE_TABLE, []string{"O"}, "Add a secondary sort column, e.g. sort by partition, then by priority"},
+	{ACTION_COLUMNS, KEYBINDING_SCOPE_TABLE, []string{"C"}, "Choose, reorder and combine columns of the current view"},
+	{ACTION_SEARCH, KEYBINDING_SCOPE_TABLE, []string{"/"}, "Open search bar to filter rows by regex, 'esc' to close, 'enter' to go back to table"},
+	{ACTION_PARTITION, KEYBINDING_SCOPE_TABLE, []string{"p"}, "Focus on partition selector, 'esc' to close"},
+	{ACTION_STATE, KEYBINDING_SCOPE_TABLE, []string{"s"}, "Focus on state selector, 'esc' to close"},
+	{ACTION_SELECT, KEYBINDING_SCOPE_TABLE, []string{KEY_SPACE}, "Select/deselect row"},
+	{ACTION_COPY, KEYBINDING_SCOPE_TABLE, []string{"y"}, "Copy selected content (either rows, or currently open details) to clipboard"},
+	{ACTION_EXPORT, KEYBINDING_SCOPE_TABLE, []string{"w"}, "Write the rows shown in the current view to a file, as CSV, JSON, plain text and more"},
+	{ACTION_COMMAND, KEYBINDING_SCOPE_TABLE, []string{"c"}, "Open 'scontrol' prompt for selected items, or current row if no selection. In the nodes view, opens a form of node actions like drain and resume"},
+	{ACTION_DETAILS, KEYBINDING_SCOPE_TABLE, []string{"Enter"}, "Show details for selected row, or the output of a command in the tasks view"},
+
+	{ACTION_CANCEL_JOB, KEYBINDING_SCOPE_JOBS, []string{"Ctrl-D"}, "Open 'scancel' prompt for selected jobs, or current row if no selection"},
+	{ACTION_JOB_USAGE, KEYBINDING_SCOPE_JOBS, []string{"u"}, "Show live usage per job step (sstat) for selected running jobs, or current row if no selection"},
+
+	{ACTION_TIME_RANGE, KEYBINDING_SCOPE_SACCT, []string{"t"}, "Focus on time range, e.g. '2h', '7d' or '2025-01-01T09:00..2025-01-01T12:00', 'enter' to apply"},
+	{ACTION_SACCT_FILTERS, KEYBINDING_SCOPE_SACCT, []string{"f"}, "Focus on sacct filters, e.g. 'user=alice account=physics state=FAILED', 'enter' to apply"},
+
+	{ACTION_ENTITY, KEYBINDING_SCOPE_SACCTMGR, []string{"e"}, "Focus on Entity type selector, 'esc' to close"},
+
+	{ACTION_RERUN_TASK, KEYBINDING_SCOPE_TASKS, []string{"r"}, "Run the command of the current row again, after confirming it"},
+	{ACTION_CANCEL_TASK, KEYBINDING_SCOPE_TASKS, []string{"Ctrl-D"}, "Stop selected running commands, or current row if no selection"},
+}
+
+// Help section headings of each scope, and lines for keys that cannot be rebound
+var keybindingScopeHelp = []struct {
+	scope   string
+	heading string
+	fixed   []string
+}{
+	{KEYBINDING_SCOPE_GLOBAL, "GENERAL SHORTCUTS", nil},
+	{KEYBINDING_SCOPE_TABLE, "SHORTCUTS IN TABLE VIEWS", []string{
+		"Arrows   Scroll up/down/left/right in table view",
+		"Esc      Close modal",
+	}},
+	{KEYBINDING_SCOPE_JOBS, "ADDITIONAL SHORTCUTS IN JOBS VIEW (SCONTROL)", nil},
+	{KEYBINDING_SCOPE_SACCT, "ADDITIONAL SHORTCUTS IN JOBS ACCOUNTING VIEW (SACCT)", nil},
+	{KEYBINDING_SCOPE_SACCTMGR, "ADDITIONAL SHORTCUTS IN ACCOUNTING MANAGER VIEW (SACCTMGR)", nil},
+	{KEYBINDING_SCOPE_TASKS, "ADDITIONAL SHORTCUTS IN TASKS VIEW", nil},
+}
+
+// Pages where the actions of each scope are available, used to find conflicting keys
+var keybindingScopePages = map[string][]string{
+	KEYBINDING_SCOPE_GLOBAL:   {"nodes", "jobs", "sacct", "sacctmgr", "sdiag", "tasks"},
+	KEYBINDING_SCOPE_TABLE:    {"nodes", "jobs", "sacct", "sacctmgr", "tasks"},
+	KEYBINDING_SCOPE_JOBS:     {"jobs"},
+	KEYBINDING_SCOPE_SACCT:    {"sacct"},
+	KEYBINDING_SCOPE_SACCTMGR: {"sacctmgr"},
+	KEYBINDING_SCOPE_TASKS:    {"tasks"},
+}
+
+// Keybindings maps each action to its keys, the defaults overridden by config files
+var Keybindings = defaultKeybindings()
+
+func defaultKeybindings() map[string][]string {
+	bindings := map[string][]string{}
+	for _, action := range KEYBINDING_ACTIONS {
+		bindings[action.Name] = slices.Clone(action.DefaultKeys)
+	}
+	return bindings
+}
+
+// applyKeybindings sets the keys of the actions in the 'keybindings' section of config files.
+// Each action is bound to a key, or a list of keys. An empty list unbinds the action.
+func applyKeybindings(keybindings map[string]any) error {
+	Keybindings = defaultKeybindings()
+	for name, value := range keybindings {
+		if _, found := Keybindings[name]; !found {
+			return fmt.Errorf("unknown action '%s', must be one of '%s'", name, strings.Join(keybindingActionNames(), "', '"))
+		}
+
+		var keys []string
+		switch value := value.(type) {
+		case []any:
+			for _, key := range value {
+				keys = append(keys, fmt.Sprint(key))
+			}
+		case nil:
+		default:
+			keys = []string{fmt.Sprint(value)}
+		}
+		for i, key := range keys {
+			parsed, err := ParseKey(key)
+			if err != nil {
+				return fmt.Errorf("invalid key '%s' for action '%s': %v", key, name, err)
+			}
+			keys[i] = parsed
+		}
+		Keybindings[name] = keys
+	}
+	return nil
+}
+
+func keybindingActionNames() (names []string) {
+	for _, action := range KEYBINDING_ACTIONS {
+		names = append(names, action.Name)
+	}
+	return
+}
+
+// Keys that terminals send the same with or without Shift and Ctrl, except for 'Shift-Tab',
+// which is sent as 'Backtab'
+var unmodifiableKeys = []string{"Enter", "Tab", "Backtab", "Esc", "Backspace", "Backspace2"}
+
+// Ctrl with these characters is sent as another key, so there is no key name for them
+var ctrlCharacterKeys = map[string]string{"H": "Backspace", "I": "Tab", "M": "Enter", "[": "Esc"}
+
+// ParseKey parses a single key, which is a character, 'Space', or a tcell key name like
+// 'Ctrl-D', 'Enter' or 'F1', optionally with 'Alt-', 'Shift-' or 'Ctrl-' modifiers, e.g. 'Alt-l'
+// or 'Shift-Left'. Returns the key named like EventKeyName names events, so that they can be compared.
+// Combinations that terminals cannot send, like 'Ctrl-Enter', are rejected.
+func ParseKey(key string) (string, error) {
+	var alt, shift, ctrl bool
+	base := key
+	for {
+		if isBaseKey(base) {
+			break
+		}
+		if rest, found := strings.CutPrefix(base, "Alt-"); found {
+			alt, base = true, rest
+		} else if rest, found := strings.CutPrefix(base, "Shift-"); found {
+			shift, base = true, rest
+		} else if rest, found := strings.CutPrefix(base, "Ctrl-"); found {
+			ctrl, base = true, rest
+		} else {
+			return "", fmt.Errorf("must be a single character, '%s' or a key name like 'Ctrl-D', 'Enter' or 'F1', optionally with 'Alt-', 'Shift-' or 'Ctrl-'", KEY_SPACE)
+		}
+	}
+
+	// Characters already tell whether shift was held, and Ctrl with a letter has its own key name
+	if len([]rune(base)) == 1 {
+		if shift {
+			base, shift = strings.ToUpper(base), false
+		}
+		if ctrl {
+			name := "Ctrl-" + strings.ToUpper(base)
+			if sentAs, found := ctrlCharacterKeys[strings.ToUpper(base)]; found {
+				return "", fmt.Errorf("'%s' is sent as '%s' by terminals", name, sentAs)
+			}
+			if !isBaseKey(name) {
+				return "", fmt.Errorf("'Ctrl-%s' is not a key", base)
+			}
+			base, ctrl = name, false
+		}
+	}
+
+	if base == "Tab" && shift && !ctrl {
+		base, shift = "Backtab", false
+	}
+	if (shift || ctrl) && (slices.Contains(unmodifiableKeys, base) || strings.HasPrefix(base, "Ctrl-")) {
+		return "", fmt.Errorf("'%s' cannot be sent by terminals", modifierPrefix(false, shift, ctrl)+base)
+	}
+	return modifierPrefix(alt, shift, ctrl) + base, nil
+}
+
+// ParseKeySequence parses one or more keys separated by spaces, e.g. 'g l' for 'g' followed by 'l'
+func ParseKeySequence(sequence string) ([]string, error) {
+	var keys []string
+	for _, key := range strings.Fields(sequence) {
+		parsed, err := ParseKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key '%s': %v", key, err)
+		}
+		keys = append(keys, parsed)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no keys given")
+	}
+	return keys, nil
+}
+
+// EventKeyName names the key of the event like in keybindings, e.g. 'o', 'Space', 'Ctrl-D' or 'Alt-l'
+func EventKeyName(event *tcell.EventKey) string {
+	mod := event.Modifiers()
+	if event.Key() == tcell.KeyRune {
+		name := string(event.Rune())
+		if event.Rune() == ' ' {
+			name = KEY_SPACE
+		}
+		return modifierPrefix(mod&tcell.ModAlt != 0, false, false) + name
+	}
+	name := tcell.KeyNames[event.Key()]
+	ctrl := mod&tcell.ModCtrl != 0 && !strings.HasPrefix(name, "Ctrl-")
+	// Shift is part of 'Backtab', which is how terminals send 'Shift-Tab'
+	shift := mod&tcell.ModShift != 0 && event.Key() != tcell.KeyBacktab
+	return modifierPrefix(mod&tcell.ModAlt != 0, shift, ctrl) + name
+}
+
+func modifierPrefix(alt, shift, ctrl bool) (prefix string) {
+	if alt {
+		prefix += "Alt-"
+	}
+	if shift {
+		prefix += "Shift-"
+	}
+	if ctrl {
+		prefix += "Ctrl-"
+	}
+	return
+}
+
+// isBaseKey checks that the key is a single character, the space bar, or a tcell key name
+func isBaseKey(key string) bool {
+	if len([]rune(key)) == 1 || key == KEY_SPACE {
+		return true
+	}
+	for _, name := range tcell.KeyNames {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateKeybindings checks that no key is bound to two actions that are available on the same
+// page, or to an action and a plugin shortcut on the plugin's page. For key sequences, the first key
+// must not be bound, as the action would run instead. Invalid plugin shortcuts are shown in the help.
+func validateKeybindings(plugins []PluginConfig) error {
+	type binding struct {
+		name  string
+		pages []string
+	}
+	keys := map[string][]binding{}
+	for _, action := range KEYBINDING_ACTIONS {
+		for _, key := range Keybindings[action.Name] {
+			keys[key] = append(keys[key], binding{fmt.Sprintf("action '%s'", action.Name), keybindingScopePages[action.Scope]})
+		}
+	}
+	for _, plugin := range plugins {
+		sequence, err := ParseKeySequence(plugin.Shortcut)
+		if err != nil {
+			continue
+		}
+		keys[sequence[0]] = append(keys[sequence[0]], binding{fmt.Sprintf("plugin '%s'", plugin.Name), []string{plugin.ActivePage}})
+	}
+
+	// Check keys in the order of the actions, so that errors are reported consistently
+	var checked []string
+	for _, action := range KEYBINDING_ACTIONS {
+		for _, key := range Keybindings[action.Name] {
+			if slices.Contains(checked, key) {
+				continue
+			}
+			checked = append(checked, key)
+			bound := keys[key]
+			for i := range bound {
+				for j := i + 1; j < len(bound); j++ {
+					if slices.ContainsFunc(bound[i].pages, func(page string) bool { return slices.Contains(bound[j].pages, page) }) {
+						return fmt.Errorf("key '%s' is bound to both %s and %s", key, bound[i].name, bound[j].name)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ActionForKey returns the action bound to the key in any of the given scopes, or an empty string
+func ActionForKey(key string, scopes ...string) string {
+	for _, action := range KEYBINDING_ACTIONS {
+		if slices.Contains(scopes, action.Scope) && slices.Contains(Keybindings[action.Name], key) {
+			return action.Name
+		}
+	}
+	return ""
+}
+
+// KeyboardShortcutsHelp lists the current keybindings of all actions, grouped by scope
+func KeyboardShortcutsHelp() string {
+	var sb strings.Builder
+	for i, section := range keybindingScopeHelp {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(section.heading + "\n")
+		for _, action := range KEYBINDING_ACTIONS {
+			if action.Scope != section.scope {
+				continue
+			}
+			keys := strings.Join(Keybindings[action.Name], "/")
+			if keys == "" {
+				keys = "(none)"
+			}
+			fmt.Fprintf(&sb, "%-9s%s\n", keys, action.Description)
+		}
+		for _, line := range section.fixed {
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		parsed string
+		err    string
+	}{
+		{key: "o", parsed: "o"},
+		{key: "O", parsed: "O"},
+		{key: "/", parsed: "/"},
+		{key: "Space", parsed: "Space"},
+		{key: "Enter", parsed: "Enter"},
+		{key: "F1", parsed: "F1"},
+		{key: "Ctrl-D", parsed: "Ctrl-D"},
+		{key: "Ctrl-d", parsed: "Ctrl-D"},
+		{key: "Ctrl-Space", parsed: "Ctrl-Space"},
+		{key: "Shift-o", parsed: "O"},
+		{key: "Alt-l", parsed: "Alt-l"},
+		{key: "Alt-Shift-l", parsed: "Alt-L"},
+		{key: "Ctrl-Alt-d", parsed: "Alt-Ctrl-D"},
+		{key: "Shift-Left", parsed: "Shift-Left"},
+		{key: "Ctrl-Shift-Left", parsed: "Shift-Ctrl-Left"},
+		{key: "Alt-Enter", parsed: "Alt-Enter"},
+		{key: "Shift-Tab", parsed: "Backtab"},
+		{key: "Backtab", parsed: "Backtab"},
+		{key: "Alt-Shift-Tab", parsed: "Alt-Backtab"},
+
+		{key: "", err: "must be a single character"},
+		{key: "oo", err: "must be a single character"},
+		{key: "Hyper-o", err: "must be a single character"},
+		{key: "Ctrl-1", err: "'Ctrl-1' is not a key"},
+		{key: "Ctrl-m", err: "'Ctrl-M' is sent as 'Enter' by terminals"},
+		{key: "Ctrl-Enter", err: "'Ctrl-Enter' cannot be sent by terminals"},
+		{key: "Shift-Enter", err: "'Shift-Enter' cannot be sent by terminals"},
+		{key: "Ctrl-Tab", err: "'Ctrl-Tab' cannot be sent by terminals"},
+		{key: "Shift-Esc", err: "'Shift-Esc' cannot be sent by terminals"},
+		{key: "Shift-Backtab", err: "'Shift-Backtab' cannot be sent by terminals"},
+		{key: "Shift-Ctrl-D", err: "'Shift-Ctrl-D' cannot be sent by terminals"},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			parsed, err := ParseKey(test.key)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.parsed, parsed)
+		})
+	}
+}
+
+func TestParseKeySequence(t *testing.T) {
+	tests := []struct {
+		sequence string
+		parsed   []string
+		err      string
+	}{
+		{sequence: "g", parsed: []string{"g"}},
+		{sequence: "g l", parsed: []string{"g", "l"}},
+		{sequence: "  Ctrl-x   Shift-Tab ", parsed: []string{"Ctrl-X", "Backtab"}},
+		{sequence: "", err: "no keys given"},
+		{sequence: "g Ctrl-Enter", err: "invalid key 'Ctrl-Enter'"},
+	}
+	for _, test := range tests {
+		t.Run(test.sequence, func(t *testing.T) {
+			parsed, err := ParseKeySequence(test.sequence)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.parsed, parsed)
+		})
+	}
+}
+
+// Keys in config files must be named the same as the events that terminals send for them
+func TestEventKeyNameMatchesParseKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		event *tcell.EventKey
+	}{
+		{"o", tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone)},
+		{"Shift-o", tcell.NewEventKey(tcell.KeyRune, 'O', tcell.ModShift)},
+		{"Space", tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone)},
+		{"Alt-l", tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModAlt)},
+		{"Enter", tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)},
+		{"Alt-Enter", tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModAlt)},
+		{"Ctrl-d", tcell.NewEventKey(tcell.KeyRune, 0x04, tcell.ModNone)},
+		{"Ctrl-Space", tcell.NewEventKey(tcell.KeyRune, 0x00, tcell.ModNone)},
+		{"Ctrl-Alt-d", tcell.NewEventKey(tcell.KeyCtrlD, 0x04, tcell.ModCtrl|tcell.ModAlt)},
+		{"Shift-Left", tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModShift)},
+		{"Ctrl-Shift-Left", tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModShift|tcell.ModCtrl)},
+		{"F5", tcell.NewEventKey(tcell.KeyF5, 0, tcell.ModNone)},
+		{"Shift-Tab", tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModShift)},
+		{"Shift-Tab", tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModNone)},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			parsed, err := ParseKey(test.key)
+			assert.NoError(t, err)
+			assert.Equal(t, parsed, EventKeyName(test.event))
+		})
+	}
+}
+
+// useKeybindings applies the keybindings for the rest of the test, restoring the defaults afterwards
+func useKeybindings(t *testing.T, keybindings map[string]any) error {
+	t.Cleanup(func() { Keybindings = defaultKeybindings() })
+	return applyKeybindings(keybindings)
+}
+
+func TestApplyKeybindings(t *testing.T) {
+	err := useKeybindings(t, map[string]any{
+		ACTION_SORT:      "S",
+		ACTION_MOVE_DOWN: []any{"j", "Ctrl-n"},
+		ACTION_COPY:      nil,
+		ACTION_MOVE_TOP:  []any{},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"S"}, Keybindings[ACTION_SORT])
+	assert.Equal(t, []string{"j", "Ctrl-N"}, Keybindings[ACTION_MOVE_DOWN])
+	assert.Empty(t, Keybindings[ACTION_COPY])
+	assert.Empty(t, Keybindings[ACTION_MOVE_TOP])
+	// Actions that are not in the config keep their defaults
+	assert.Equal(t, []string{"O"}, Keybindings[ACTION_THEN_SORT])
+
+	// Each config replaces the keybindings of the previous one, instead of adding to them
+	assert.NoError(t, applyKeybindings(map[string]any{ACTION_THEN_SORT: "T"}))
+	assert.Equal(t, []string{"o"}, Keybindings[ACTION_SORT])
+	assert.Equal(t, []string{"T"}, Keybindings[ACTION_THEN_SORT])
+
+	err = useKeybindings(t, map[string]any{"explode": "x"})
+	assert.ErrorContains(t, err, "unknown action 'explode', must be one of 'nodes-view', ")
+
+	err = useKeybindings(t, map[string]any{ACTION_SORT: []any{"o", "Ctrl-Enter"}})
+	assert.ErrorContains(t, err, "invalid key 'Ctrl-Enter' for action 'sort'")
+}
+
+func TestValidateKeybindings(t *testing.T) {
+	tests := []struct {
+		name        string
+		keybindings map[string]any
+		plugins     []PluginConfig
+		err         string
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:        "same key in different views",
+			keybindings: map[string]any{ACTION_TIME_RANGE: "e"},
+		},
+		{
+			name:    "plugin on a free key",
+			plugins: []PluginConfig{{Name: "logs", ActivePage: "jobs", Shortcut: "L"}},
+		},
+		{
+			name:    "plugin with a sequence of free keys",
+			plugins: []PluginConfig{{Name: "logs", ActivePage: "jobs", Shortcut: "x l"}},
+		},
+		{
+			name:    "invalid plugin shortcuts are left for the help",
+			plugins: []PluginConfig{{Name: "logs", ActivePage: "jobs", Shortcut: "Ctrl-Enter"}},
+		},
+		{
+			name:        "two table actions",
+			keybindings: map[string]any{ACTION_SORT: "j"},
+			err:         "key 'j' is bound to both action 'move-down' and action 'sort'",
+		},
+		{
+			name:        "global and view action",
+			keybindings: map[string]any{ACTION_CANCEL_JOB: "?"},
+			err:         "key '?' is bound to both action 'help' and action 'cancel-job'",
+		},
+		{
+			name:    "plugin on a bound key",
+			plugins: []PluginConfig{{Name: "logs", ActivePage: "jobs", Shortcut: "u"}},
+			err:     "key 'u' is bound to both action 'job-usage' and plugin 'logs'",
+		},
+		{
+			name:    "plugin sequence starting with a bound key",
+			plugins: []PluginConfig{{Name: "logs", ActivePage: "nodes", Shortcut: "g l"}},
+			err:     "key 'g' is bound to both action 'move-top' and plugin 'logs'",
+		},
+		{
+			name:        "plugin on a key freed by keybindings",
+			keybindings: map[string]any{ACTION_MOVE_TOP: "Home"},
+			plugins:     []PluginConfig{{Name: "logs", ActivePage: "nodes", Shortcut: "g l"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.NoError(t, useKeybindings(t, test.keybindings))
+			err := validateKeybindings(test.plugins)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestActionForKey(t *testing.T) {
+	assert.NoError(t, useKeybindings(t, map[string]any{ACTION_SORT: []any{"S", "Alt-s"}}))
+
+	assert.Equal(t, ACTION_SORT, ActionForKey("S", KEYBINDING_SCOPE_TABLE))
+	assert.Equal(t, ACTION_SORT, ActionForKey("Alt-s", KEYBINDING_SCOPE_GLOBAL, KEYBINDING_SCOPE_TABLE))
+	assert.Equal(t, "", ActionForKey("o", KEYBINDING_SCOPE_TABLE), "default key of a rebound action")
+	assert.Equal(t, "", ActionForKey("S", KEYBINDING_SCOPE_GLOBAL), "action of another scope")
+
+	// The same key is bound in several scopes, and the action of the given scope is returned
+	assert.Equal(t, ACTION_CANCEL_JOB, ActionForKey("Ctrl-D", KEYBINDING_SCOPE_JOBS))
+	assert.Equal(t, ACTION_CANCEL_TASK, ActionForKey("Ctrl-D", KEYBINDING_SCOPE_TASKS))
+}
+
+func TestKeyboardShortcutsHelp(t *testing.T) {
+	assert.NoError(t, useKeybindings(t, map[string]any{
+		ACTION_SORT: []any{"S", "Alt-s"},
+		ACTION_COPY: nil,
+	}))
+
+	help := KeyboardShortcutsHelp()
+	assert.Contains(t, help, "GENERAL SHORTCUTS\n1        Switch to Nodes view (scontrol)\n")
+	assert.Contains(t, help, "\nS/Alt-s  Sort table by column")
+	assert.Contains(t, help, "\n(none)   Copy selected content")
+	assert.Contains(t, help, "\nEsc      Close modal\n\nADDITIONAL SHORTCUTS IN JOBS VIEW (SCONTROL)\n")
+	for _, action := range KEYBINDING_ACTIONS {
+		assert.Contains(t, help, action.Description)
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// ProfileConfig is a named set of settings and plugins, typically one for each cluster.
+// Profile settings take precedence over settings outside of profiles.
+type ProfileConfig struct {
+	Name     string         `yaml:"name"`
+	Settings map[string]any `yaml:"settings"`
+	Plugins  []PluginConfig `yaml:"plugins"`
+}
+
+var (
+	loadedConfig        Config            // All config files merged, before applying a profile
+	startupFlagValues   map[string]string // Flag values before applying a profile
+	startupSlurmConf    string            // SLURM_CONF env var at startup
+	startupHasSlurmConf bool
+)
+
+// ProfileNames lists the names of all profiles in config files, in the order they were defined
+func ProfileNames() (names []string) {
+	for _, profile := range loadedConfig.Profiles {
+		names = append(names, profile.Name)
+	}
+	return
+}
+
+// SwitchProfile switches to the given profile at runtime, and connects to the cluster again.
+// Settings not set by the profile are reset to their startup values, and profile settings take
+// precedence over command line flags. On failure, the previous profile is restored.
+func SwitchProfile(name string) error {
+	previousProfile := ActiveProfile
+	err := switchProfile(name)
+	if err != nil {
+		if restoreErr := switchProfile(previousProfile); restoreErr != nil {
+			return fmt.Errorf("%v, and failed to restore profile '%s': %v", err, previousProfile, restoreErr)
+		}
+	}
+	return err
+}
+
+func switchProfile(name string) error {
+	restoreStartupFlagValues()
+	if err := applyProfile(name, true); err != nil {
+		return err
+	}
+
+	// Reset filters chosen in the UI, as they may not apply to the new cluster
+	SacctStartTime, SacctEndTime = "", ""
+	NodeStateCurrentChoice, JobStateCurrentChoice = ALL_CATEGORIES_OPTION, ALL_CATEGORIES_OPTION
+
+	// The new cluster may run another Slurm version
+	ResetJSONOutput()
+	return connectToCluster()
+}
+
+// applyProfile applies the settings of the given profile, and sets the plugins in ConfigFile.
+// An empty name applies no profile.
+func applyProfile(name string, overrideCommandLine bool) error {
+	ConfigFile = Config{
+		Plugins:     slices.Clone(loadedConfig.Plugins),
+		Settings:    loadedConfig.Settings,
+		Profiles:    loadedConfig.Profiles,
+		Keybindings: loadedConfig.Keybindings,
+	}
+	ActiveProfile = name
+	if name == "" {
+		return nil
+	}
+
+	index := slices.IndexFunc(loadedConfig.Profiles, func(p ProfileConfig) bool { return p.Name == name })
+	if index < 0 {
+		return fmt.Errorf("unknown profile '%s', must be one of %v", name, ProfileNames())
+	}
+	profile := loadedConfig.Profiles[index]
+	ConfigFile.Plugins = append(ConfigFile.Plugins, profile.Plugins...)
+	if err := applySettings(profile.Settings, overrideCommandLine); err != nil {
+		return fmt.Errorf("invalid settings in profile '%s': %v", name, err)
+	}
+	return nil
+}
+
+// saveStartupFlagValues stores the values of all flags, so they can be restored when switching profiles
+func saveStartupFlagValues() {
+	startupFlagValues = map[string]string{}
+	flag.VisitAll(func(f *flag.Flag) {
+		startupFlagValues[f.Name] = f.Value.String()
+	})
+}
+
+func restoreStartupFlagValues() {
+	for name, value := range startupFlagValues {
+		flag.Set(name, value)
+	}
+}
+
+func saveSlurmConfEnv() {
+	startupSlurmConf, startupHasSlurmConf = os.LookupEnv("SLURM_CONF")
+}
+
+// restoreSlurmConfEnv resets SLURM_CONF to its value at startup, in case a profile had changed it
+func restoreSlurmConfEnv() {
+	if startupHasSlurmConf {
+		os.Setenv("SLURM_CONF", startupSlurmConf)
+	} else {
+		os.Unsetenv("SLURM_CONF")
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSlurmBinaries writes 'scontrol' and 'sacctmgr' scripts for a cluster of the given name into a
+// new directory, and returns the directory
+func fakeSlurmBinaries(t *testing.T, clusterName string) string {
+	dir := t.TempDir()
+	scontrol := fmt.Sprintf("#!/bin/sh\nif [ \"$1\" = show ]; then echo 'ClusterName = %s'; echo 'SLURM_VERSION = 24.05.1'; fi\n", clusterName)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scontrol"), []byte(scontrol), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sacctmgr"), []byte("#!/bin/sh\n"), 0o755))
+	return dir
+}
+
+// useProfiles loads the config file with profiles, and connects to the cluster of the default profile
+func useProfiles(t *testing.T, content string, flags map[string]string) {
+	// Runs after the flags are reset by useCommandLineFlags
+	t.Cleanup(func() {
+		ResetJSONOutput()
+		ComputeConfigurations()
+		SchedulerHostName, ClusterName, SchedulerSlurmVersion = "", "", ""
+		SacctEnabled = false
+	})
+	useCommandLineFlags(t, flags)
+	loadedConfig = loadConfigDirs([]string{writeConfig(t, content)})
+	require.NoError(t, applySettings(loadedConfig.Settings, false))
+	saveStartupFlagValues()
+	require.NoError(t, applyProfile("", false))
+	require.NoError(t, connectToCluster())
+}
+
+func TestSwitchProfile(t *testing.T) {
+	useProfiles(t, fmt.Sprintf(`
+settings:
+  slurm-binaries-path: %s
+profiles:
+  - name: federation
+    settings:
+      slurm-binaries-path: %s
+      clusters: alpha,beta
+      partition: gpu
+`, fakeSlurmBinaries(t, "local"), fakeSlurmBinaries(t, "alpha")), nil)
+	assert.Equal(t, "local", ClusterName)
+
+	// Filters chosen in the UI and the JSON output latch are reset
+	SacctStartTime, SacctEndTime = "2025-01-01T00:00", "2025-01-02T00:00"
+	NodeStateCurrentChoice, JobStateCurrentChoice = "IDLE", "RUNNING"
+	DisableJSONOutput("local")
+
+	require.NoError(t, SwitchProfile("federation"))
+	assert.Equal(t, "federation", ActiveProfile)
+	assert.Equal(t, "alpha", ClusterName)
+	assert.Equal(t, "alpha,beta", Clusters)
+	assert.Equal(t, "gpu", PartitionFilter)
+	assert.Equal(t, "", SacctStartTime)
+	assert.Equal(t, "", SacctEndTime)
+	assert.Equal(t, ALL_CATEGORIES_OPTION, NodeStateCurrentChoice)
+	assert.Equal(t, ALL_CATEGORIES_OPTION, JobStateCurrentChoice)
+	assert.False(t, JSONOutputUnavailable("local"))
+	assert.True(t, slices.ContainsFunc(*NodeViewColumns, func(c ColumnConfig) bool { return c.RawName == "Cluster" }))
+
+	// Switching back restores the settings from before the profile, including the columns
+	require.NoError(t, SwitchProfile(""))
+	assert.Equal(t, "", ActiveProfile)
+	assert.Equal(t, "local", ClusterName)
+	assert.Equal(t, "", Clusters)
+	assert.Equal(t, ALL_CATEGORIES_OPTION, PartitionFilter)
+	assert.False(t, slices.ContainsFunc(*NodeViewColumns, func(c ColumnConfig) bool { return c.RawName == "Cluster" }))
+}
+
+func TestSwitchProfileKeepsCommandLineFlagsUnlessSet(t *testing.T) {
+	useProfiles(t, fmt.Sprintf(`
+settings:
+  slurm-binaries-path: %s
+profiles:
+  - name: other
+    settings:
+      partition: gpu
+`, fakeSlurmBinaries(t, "local")), map[string]string{"partition": "debug", "sacct-users": "alice"})
+
+	require.NoError(t, SwitchProfile("other"))
+	assert.Equal(t, "gpu", PartitionFilter, "profile settings take precedence over flags")
+	assert.Equal(t, "alice", SacctUsers)
+
+	require.NoError(t, SwitchProfile(""))
+	assert.Equal(t, "debug", PartitionFilter)
+}
+
+func TestSwitchProfileRestoresPreviousProfileOnFailure(t *testing.T) {
+	useProfiles(t, fmt.Sprintf(`
+settings:
+  slurm-binaries-path: %s
+profiles:
+  - name: unreachable
+    settings:
+      slurm-binaries-path: %s
+`, fakeSlurmBinaries(t, "local"), t.TempDir()), nil)
+
+	err := SwitchProfile("unreachable")
+	assert.ErrorContains(t, err, "Failed to connect to Slurm")
+	assert.Equal(t, "", ActiveProfile)
+	assert.Equal(t, "local", ClusterName)
+
+	assert.ErrorContains(t, SwitchProfile("missing"), "unknown profile 'missing'")
+	assert.Equal(t, "", ActiveProfile)
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/antvirf/stui/internal/slurmrestd"
+)
+
+// Validate slurmrestd related options and load the JWT token, either from
+// the given token file or from the SLURM_JWT env var.
+func configureSlurmRestd() error {
+	if SlurmRestdURL == "" {
+		return errors.New("'slurmrestd-url' must be set when using the slurmrestd backend")
+	}
+
+	if SlurmRestdTokenFile != "" {
+		raw, err := os.ReadFile(SlurmRestdTokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read token file: %v", err)
+		}
+		SlurmRestdToken = strings.TrimSpace(string(raw))
+	} else {
+		SlurmRestdToken = strings.TrimSpace(os.Getenv("SLURM_JWT"))
+	}
+
+	// Recordings don't need a token to be replayed
+	if SlurmRestdToken == "" && ReplayDir == "" {
+		return errors.New("no JWT found, set SLURM_JWT or 'slurmrestd-token-file'")
+	}
+	return nil
+}
+
+// NewSlurmRestdClient returns a slurmrestd client configured from the current settings.
+func NewSlurmRestdClient() *slurmrestd.Client {
+	return slurmrestd.NewClient(SlurmRestdURL, SlurmRestdAPIVersion, SlurmRestdToken)
+}
+
+// Check whether the cluster is reachable via slurmrestd's ping endpoint
+func checkIfSlurmRestdIsReachable() error {
+	ctx, cancel := context.WithTimeout(context.Background(), RequestTimeout)
+	defer cancel()
+
+	client := NewSlurmRestdClient()
+	_, err := client.Get(ctx, client.SlurmPath("ping"))
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("slurmrestd did not respond within configured timeout %s", RequestTimeout)
+	}
+	return err
+}
+
+// Check whether accounting is available via slurmrestd, equivalent to 'sacctmgr show cluster'
+func checkIfSlurmdbIsAvailableViaSlurmRestd() {
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	client := NewSlurmRestdClient()
+	_, err := client.Get(ctx, client.SlurmdbPath("clusters"))
+	SacctEnabled = err == nil
+}
+
+// Fetch scheduler info from slurmrestd. The ping response contains the controller
+// hostnames, and all responses carry cluster name and version in their 'meta' object.
+func getSchedulerInfoViaSlurmRestd(timeout time.Duration) (schedulerHostName, clusterName, slurmVersion string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client := NewSlurmRestdClient()
+	body, err := client.Get(ctx, client.SlurmPath("ping"))
+	if err != nil {
+		return "(failed to fetch scheduler info)", "", ""
+	}
+
+	var parsed struct {
+		Meta struct {
+			Slurm struct {
+				Release string `json:"release"`
+				Cluster string `json:"cluster"`
+			} `json:"slurm"`
+		} `json:"meta"`
+		Pings []struct {
+			Hostname string `json:"hostname"`
+		} `json:"pings"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return "(failed to fetch scheduler info)", "", ""
+	}
+
+	if len(parsed.Pings) > 0 {
+		schedulerHostName = parsed.Pings[0].Hostname
+	}
+	return schedulerHostName, parsed.Meta.Slurm.Cluster, parsed.Meta.Slurm.Release
+}
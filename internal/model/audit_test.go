@@ -0,0 +1,88 @@
+package model
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/antvirf/stui/internal/audit"
+	"github.com/antvirf/stui/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAuditLog returns all entries of the audit log
+func readAuditLog(t *testing.T, file string) []audit.Entry {
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []audit.Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry audit.Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
+
+func TestTasksAreAudited(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	require.NoError(t, audit.Enable(file))
+	defer audit.Disable()
+
+	previousCluster := config.ClusterName
+	defer func() { config.ClusterName = previousCluster }()
+	config.ClusterName = "testcluster"
+
+	task := StartTask("echo drained; exit 2", "nodes", TaskOptions{Targets: []string{"node1", "node2"}}, nil)
+	waitForTask(t, task)
+	cancelled := StartTask("sleep 10", "jobs", TaskOptions{}, nil)
+	cancelled.Kill()
+	waitForTask(t, cancelled)
+
+	entries := readAuditLog(t, file)
+	require.Len(t, entries, 4)
+
+	start, end := entries[0], entries[1]
+	assert.Equal(t, audit.EVENT_START, start.Event)
+	assert.Equal(t, task.ID, start.Task)
+	assert.Equal(t, "testcluster", start.Cluster)
+	assert.Equal(t, "nodes", start.Page)
+	assert.Equal(t, []string{"node1", "node2"}, start.Targets)
+	assert.Equal(t, "echo drained; exit 2", start.Command)
+	assert.NotEmpty(t, start.User)
+	assert.Nil(t, start.ExitCode)
+	assert.Empty(t, start.OutputSHA256)
+
+	assert.Equal(t, audit.EVENT_END, end.Event)
+	assert.Equal(t, task.ID, end.Task)
+	assert.Equal(t, TASK_STATUS_FAILED, end.Status)
+	require.NotNil(t, end.ExitCode)
+	assert.Equal(t, 2, *end.ExitCode)
+	hash := sha256.Sum256([]byte("drained\n"))
+	assert.Equal(t, hex.EncodeToString(hash[:]), end.OutputSHA256)
+
+	// Commands that did not exit by themselves are logged as well
+	assert.Equal(t, cancelled.ID, entries[3].Task)
+	assert.Equal(t, TASK_STATUS_CANCELLED, entries[3].Status)
+	assert.Equal(t, -1, *entries[3].ExitCode)
+	assert.Equal(t, []string{}, entries[3].Targets)
+
+	// Commands are not run at all if they cannot be audited
+	require.NoError(t, os.Remove(file))
+	require.NoError(t, os.Mkdir(file, 0o755))
+	marker := filepath.Join(t.TempDir(), "ran")
+	task = StartTask("touch "+marker, "nodes", TaskOptions{}, nil)
+	waitForTask(t, task)
+
+	assert.Equal(t, TASK_STATUS_FAILED, task.Status())
+	assert.Contains(t, task.Output(), "command not run, failed to write audit log")
+	assert.NoFileExists(t, marker)
+}
@@ -0,0 +1,171 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/logger"
+	"github.com/antvirf/stui/internal/recorder"
+)
+
+// Classes of errors returned by runSlurmCommand, use errors.Is to check for them
+var (
+	ErrCommandTimeout  = errors.New("command timed out")
+	ErrCommandNotFound = errors.New("command not found")
+	ErrCommandFailed   = errors.New("command failed")
+)
+
+// Names of the classes of errors in recordings
+var commandErrorKindNames = map[error]string{
+	ErrCommandTimeout:  "timeout",
+	ErrCommandNotFound: "not-found",
+	ErrCommandFailed:   "failed",
+}
+
+// CommandError describes a failed execution of a Slurm binary
+type CommandError struct {
+	Kind     error // One of ErrCommandTimeout, ErrCommandNotFound or ErrCommandFailed
+	Binary   string
+	Timeout  time.Duration
+	ExitCode int
+	Stderr   string
+	Err      error
+	message  string // Message of a replayed error, shown as recorded
+}
+
+func (e *CommandError) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+	switch e.Kind {
+	case ErrCommandTimeout:
+		return fmt.Sprintf("timeout after %v", e.Timeout)
+	case ErrCommandNotFound:
+		return fmt.Sprintf("%s not found, ensure it is in $PATH or set 'slurm-binaries-path'", e.Binary)
+	}
+	if e.Stderr != "" {
+		return fmt.Sprintf("%s failed with exit code %d: %s", e.Binary, e.ExitCode, e.Stderr)
+	}
+	return fmt.Sprintf("%s failed: %v", e.Binary, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Kind
+}
+
+// RecordedDetails implements recorder.RecordableError
+func (e *CommandError) RecordedDetails() (string, int, string) {
+	return commandErrorKindNames[e.Kind], e.ExitCode, e.Stderr
+}
+
+// replayedCommandError rebuilds the CommandError of a recording, so that it is handled the same
+// as when it was recorded. Errors recorded without a kind are returned as they are.
+func replayedCommandError(command slurmCommand, replayed *recorder.ReplayedError) error {
+	for kind, name := range commandErrorKindNames {
+		if name == replayed.Kind {
+			return &CommandError{
+				Kind:     kind,
+				Binary:   command.Binary,
+				Timeout:  command.Timeout,
+				ExitCode: replayed.ExitCode,
+				Stderr:   replayed.Stderr,
+				Err:      replayed,
+				message:  replayed.Message,
+			}
+		}
+	}
+	return replayed
+}
+
+// slurmCommand is a single execution of a Slurm binary
+type slurmCommand struct {
+	Binary  string   // Name of the binary, e.g. 'scontrol', looked up from config.SlurmBinariesPath
+	Args    []string // Arguments, passed to the binary as-is
+	Stdin   string   // Optional input, for commands that are interactive
+	Timeout time.Duration
+}
+
+func (c slurmCommand) path() string {
+	return path.Join(config.SlurmBinariesPath, c.Binary)
+}
+
+// String returns the command line, used for logging and recordings
+func (c slurmCommand) String() string {
+	return strings.Join(append([]string{c.path()}, c.Args...), " ")
+}
+
+// runSlurmCommand executes the given command with its timeout and returns its stdout.
+// All executions of Slurm binaries should go through here, so that they are counted,
+// logged and can be recorded or replayed the same way.
+func runSlurmCommand(command slurmCommand) ([]byte, error) {
+	startTime := time.Now()
+	FetchCounter.increment()
+	commandLine := command.String()
+
+	out, err := recorder.Capture(commandLine, func() ([]byte, error) {
+		return execSlurmCommand(command)
+	})
+	var replayed *recorder.ReplayedError
+	if errors.As(err, &replayed) {
+		err = replayedCommandError(command, replayed)
+	}
+	execTime := time.Since(startTime).Milliseconds()
+
+	switch {
+	case errors.Is(err, ErrCommandTimeout):
+		logger.Debugf("%s: timed out after %dms: %s", command.Binary, execTime, commandLine)
+	case err != nil:
+		logger.Debugf("%s: failed after %dms: %s (%v)", command.Binary, execTime, commandLine, err)
+	default:
+		logger.Debugf("%s: completed in %dms: %s", command.Binary, execTime, commandLine)
+	}
+	return out, err
+}
+
+// execSlurmCommand runs the command, classifying any failure into a CommandError
+func execSlurmCommand(command slurmCommand) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), command.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command.path(), command.Args...)
+	if command.Stdin != "" {
+		cmd.Stdin = strings.NewReader(command.Stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait for the output of child processes that outlive a killed command
+	cmd.WaitDelay = 100 * time.Millisecond
+
+	err := cmd.Run()
+	if err == nil {
+		return stdout.Bytes(), nil
+	}
+
+	commandError := &CommandError{
+		Kind:     ErrCommandFailed,
+		Binary:   command.Binary,
+		Timeout:  command.Timeout,
+		ExitCode: -1,
+		Stderr:   strings.TrimSpace(stderr.String()),
+		Err:      err,
+	}
+	var exitError *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		commandError.Kind = ErrCommandTimeout
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		commandError.Kind = ErrCommandNotFound
+	case errors.As(err, &exitError):
+		commandError.ExitCode = exitError.ExitCode()
+	}
+	return stdout.Bytes(), commandError
+}
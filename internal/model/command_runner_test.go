@@ -0,0 +1,71 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useFakeSlurmBinaries points the config at a directory containing the given shell scripts,
+// in a path with a space in it, to ensure arguments are passed to binaries as-is.
+func useFakeSlurmBinaries(t *testing.T, scripts map[string]string) {
+	dir := filepath.Join(t.TempDir(), "slurm binaries")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	for name, script := range scripts {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755))
+	}
+
+	previousPath := config.SlurmBinariesPath
+	config.SlurmBinariesPath = dir
+	t.Cleanup(func() { config.SlurmBinariesPath = previousPath })
+}
+
+func TestRunSlurmCommand(t *testing.T) {
+	useFakeSlurmBinaries(t, map[string]string{
+		"scontrol": `printf '%s\n' "$@"`,
+		"sacct":    `echo "sacct: error: Problem talking to the database" >&2; exit 2`,
+		"sdiag":    `sleep 5`,
+		"sacctmgr": `cat`,
+	})
+
+	out, err := runSlurmCommand(slurmCommand{
+		Binary:  "scontrol",
+		Args:    []string{"show", "job", "name with spaces"},
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "show\njob\nname with spaces\n", string(out))
+
+	out, err = runSlurmCommand(slurmCommand{Binary: "sacctmgr", Stdin: "no", Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, "no", string(out))
+
+	_, err = runSlurmCommand(slurmCommand{Binary: "sacct", Timeout: time.Second})
+	assert.ErrorIs(t, err, ErrCommandFailed)
+	assert.EqualError(t, err, "sacct failed with exit code 2: sacct: error: Problem talking to the database")
+
+	_, err = runSlurmCommand(slurmCommand{Binary: "sdiag", Timeout: 100 * time.Millisecond})
+	assert.ErrorIs(t, err, ErrCommandTimeout)
+	assert.EqualError(t, err, "timeout after 100ms")
+
+	_, err = runSlurmCommand(slurmCommand{Binary: "sinfo", Timeout: time.Second})
+	assert.ErrorIs(t, err, ErrCommandNotFound)
+}
+
+// The checks of the config package run through the same runner, and are counted and classified
+func TestConfigChecksUseSlurmCommandRunner(t *testing.T) {
+	useFakeSlurmBinaries(t, map[string]string{
+		"scontrol": `echo "Slurmctld(primary) at ctl is DOWN"; exit 1`,
+	})
+
+	countBefore := FetchCounter.Count
+	out, err := config.RunSlurmCommand("scontrol", []string{"ping"}, time.Second)
+	assert.ErrorIs(t, err, ErrCommandFailed)
+	assert.Equal(t, "Slurmctld(primary) at ctl is DOWN\n", string(out))
+	assert.Equal(t, countBefore+1, FetchCounter.Count)
+}
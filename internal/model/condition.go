@@ -0,0 +1,150 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Operators of conditions, comparisons are joined by '&&' and '||'
+const (
+	CONDITION_EQUALS      = "=="
+	CONDITION_NOT_EQUALS  = "!="
+	CONDITION_MATCHES     = "=~"
+	CONDITION_NOT_MATCHES = "!~"
+	CONDITION_AND         = "&&"
+	CONDITION_OR          = "||"
+)
+
+var conditionOperators = []string{
+	CONDITION_EQUALS, CONDITION_NOT_EQUALS, CONDITION_MATCHES, CONDITION_NOT_MATCHES, CONDITION_AND, CONDITION_OR,
+}
+
+// MatchesCondition evaluates a condition on the fields of a row, as used by the 'when' of plugins.
+// A condition is either comparisons like 'JobState == "RUNNING" && Partition != debug', with
+// '=~' and '!~' for regex matches, joined by '&&' and '||', where '&&' takes precedence, or a Go
+// template like '{{ eq .JobState "RUNNING" }}' that renders to 'true'. An empty condition matches.
+func MatchesCondition(condition string, row map[string]string) (bool, error) {
+	if strings.TrimSpace(condition) == "" {
+		return true, nil
+	}
+	if strings.Contains(condition, "{{") {
+		tmpl, err := template.New("condition").Option("missingkey=error").Parse(condition)
+		if err != nil {
+			return false, err
+		}
+		var output bytes.Buffer
+		if err := tmpl.Execute(&output, row); err != nil {
+			return false, err
+		}
+		return strings.TrimSpace(output.String()) == "true", nil
+	}
+
+	tokens, err := tokenizeCondition(condition)
+	if err != nil {
+		return false, err
+	}
+	// Every comparison is evaluated, so that invalid conditions are reported even if they would
+	// not change the result
+	matches := false
+	for _, alternative := range splitTokens(tokens, CONDITION_OR) {
+		allMatch := true
+		for _, comparison := range splitTokens(alternative, CONDITION_AND) {
+			match, err := evaluateComparison(comparison, row)
+			if err != nil {
+				return false, err
+			}
+			allMatch = allMatch && match
+		}
+		matches = matches || allMatch
+	}
+	return matches, nil
+}
+
+// tokenizeCondition splits a condition into field names, operators and values. Quoted values
+// keep their quotes, so that they are not mistaken for operators.
+func tokenizeCondition(condition string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(condition); {
+		switch {
+		case condition[i] == ' ' || condition[i] == '\t':
+			i++
+		case condition[i] == '"' || condition[i] == '\'':
+			end := strings.IndexByte(condition[i+1:], condition[i])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, condition[i:i+end+2])
+			i += end + 2
+		case isConditionOperatorAt(condition, i):
+			tokens = append(tokens, condition[i:i+2])
+			i += 2
+		default:
+			start := i
+			for i < len(condition) && !strings.ContainsRune(" \t\"'", rune(condition[i])) && !isConditionOperatorAt(condition, i) {
+				i++
+			}
+			tokens = append(tokens, condition[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+func isConditionOperatorAt(condition string, i int) bool {
+	if i+2 > len(condition) {
+		return false
+	}
+	return slices.Contains(conditionOperators, condition[i:i+2])
+}
+
+func splitTokens(tokens []string, separator string) [][]string {
+	parts := [][]string{{}}
+	for _, token := range tokens {
+		if token == separator {
+			parts = append(parts, []string{})
+			continue
+		}
+		parts[len(parts)-1] = append(parts[len(parts)-1], token)
+	}
+	return parts
+}
+
+// evaluateComparison evaluates a single 'field operator value' comparison
+func evaluateComparison(tokens []string, row map[string]string) (bool, error) {
+	if len(tokens) != 3 {
+		return false, fmt.Errorf("expected a comparison like 'Field == value', got '%s'", strings.Join(tokens, " "))
+	}
+	field, operator, value := tokens[0], tokens[1], unquoteConditionValue(tokens[2])
+	if isConditionOperatorAt(field, 0) || field != unquoteConditionValue(field) {
+		return false, fmt.Errorf("expected a field name, got '%s'", field)
+	}
+	actual, found := row[field]
+	if !found {
+		return false, fmt.Errorf("unknown field '%s', only columns shown in the view can be used", field)
+	}
+
+	switch operator {
+	case CONDITION_EQUALS:
+		return actual == value, nil
+	case CONDITION_NOT_EQUALS:
+		return actual != value, nil
+	case CONDITION_MATCHES, CONDITION_NOT_MATCHES:
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return false, fmt.Errorf("invalid regex '%s': %v", value, err)
+		}
+		return pattern.MatchString(actual) == (operator == CONDITION_MATCHES), nil
+	}
+	return false, fmt.Errorf("unknown operator '%s', must be one of '%s', '%s', '%s' or '%s'",
+		operator, CONDITION_EQUALS, CONDITION_NOT_EQUALS, CONDITION_MATCHES, CONDITION_NOT_MATCHES)
+}
+
+func unquoteConditionValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
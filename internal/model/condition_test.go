@@ -0,0 +1,57 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesCondition(t *testing.T) {
+	row := map[string]string{"JobState": "RUNNING", "Partition": "gpu", "UserId": "alice(1001)", "CPUs/Task": "4"}
+
+	tests := []struct {
+		condition string
+		expected  bool
+	}{
+		{"", true},
+		{`JobState == "RUNNING"`, true},
+		{`JobState=="PENDING"`, false},
+		{"JobState != PENDING", true},
+		{`Partition == 'gpu' && JobState == "RUNNING"`, true},
+		{`Partition == debug && JobState == RUNNING`, false},
+		{`Partition == debug || JobState == RUNNING`, true},
+		{`Partition == debug || JobState == PENDING && UserId =~ alice`, false},
+		{`UserId =~ "^alice\("`, true},
+		{`UserId !~ bob`, true},
+		{`CPUs/Task == 4`, true},
+		{`{{ eq .JobState "RUNNING" }}`, true},
+		{`{{ if eq .Partition "debug" }}true{{ end }}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			matches, err := MatchesCondition(tt.condition, row)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, matches)
+		})
+	}
+}
+
+func TestMatchesConditionErrors(t *testing.T) {
+	row := map[string]string{"JobState": "RUNNING"}
+
+	for condition, expected := range map[string]string{
+		`State == RUNNING`:                       "unknown field 'State'",
+		`JobState = RUNNING`:                     "unknown operator '='",
+		`JobState ==`:                            "expected a comparison",
+		`JobState == "RUNNING`:                   "unterminated string",
+		`JobState =~ "("`:                        "invalid regex",
+		`"JobState" == RUNNING`:                  "expected a field name",
+		`JobState == RUNNING && State == FAILED`: "unknown field 'State'",
+		`{{ .State }}`:                           "map has no entry for key",
+	} {
+		t.Run(condition, func(t *testing.T) {
+			_, err := MatchesCondition(condition, row)
+			assert.ErrorContains(t, err, expected)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package model
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/antvirf/stui/internal/config"
+)
+
+// Output formats of 'stui export', and of exporting a table to a file from the UI
+const (
+	EXPORT_FORMAT_CSV      = "csv"
+	EXPORT_FORMAT_JSON     = "json"
+	EXPORT_FORMAT_TSV      = "tsv"
+	EXPORT_FORMAT_MARKDOWN = "markdown"
+	EXPORT_FORMAT_TEXT     = "text"
+)
+
+var EXPORT_FORMATS = []string{EXPORT_FORMAT_CSV, EXPORT_FORMAT_JSON, EXPORT_FORMAT_TSV, EXPORT_FORMAT_MARKDOWN, EXPORT_FORMAT_TEXT}
+
+// File extensions for each export format
+var EXPORT_FILE_EXTENSIONS = map[string]string{
+	EXPORT_FORMAT_CSV:      ".csv",
+	EXPORT_FORMAT_JSON:     ".json",
+	EXPORT_FORMAT_TSV:      ".tsv",
+	EXPORT_FORMAT_MARKDOWN: ".md",
+	EXPORT_FORMAT_TEXT:     ".txt",
+}
+
+// Views of 'stui export'. sacctmgr views are given with the entity, e.g. 'sacctmgr:QOS'
+const (
+	EXPORT_VIEW_NODES           = "nodes"
+	EXPORT_VIEW_JOBS            = "jobs"
+	EXPORT_VIEW_SACCT           = "sacct"
+	EXPORT_VIEW_SACCTMGR_PREFIX = "sacctmgr:"
+)
+
+// Export fetches the view chosen with the export flags, applies the same partition, state and
+// search filters as the UI, and writes the rows to w in the chosen format.
+func Export(w io.Writer) error {
+	if !slices.Contains(EXPORT_FORMATS, config.ExportFormat) {
+		return fmt.Errorf("unknown format '%s', must be one of '%s'", config.ExportFormat, strings.Join(EXPORT_FORMATS, "', '"))
+	}
+
+	data, err := exportData(NewFetcher(), config.ExportView, config.ExportState)
+	if err != nil {
+		return err
+	}
+	data, err = data.ApplySearch(config.ExportSearch)
+	if err != nil {
+		return fmt.Errorf("invalid search pattern: %v", err)
+	}
+	return WriteTableData(w, data, config.ExportFormat)
+}
+
+// exportData fetches the given view with the providers used by the UI, filtered by state
+func exportData(fetcher Fetcher, view string, state string) (*TableData, error) {
+	// States are matched like in the state selector, where all states are upper case
+	state = strings.ToUpper(state)
+	if state == "" {
+		state = config.ALL_CATEGORIES_OPTION
+	}
+
+	var provider DataProvider[*TableData]
+	switch {
+	case view == EXPORT_VIEW_NODES:
+		config.NodeStateCurrentChoice = state
+		provider = NewNodesProvider(fetcher)
+	case view == EXPORT_VIEW_JOBS:
+		config.JobStateCurrentChoice = state
+		provider = NewJobsProvider(fetcher)
+	case view == EXPORT_VIEW_SACCT:
+		if !config.SacctEnabled {
+			return nil, errors.New("sacct is not available on this cluster")
+		}
+		config.JobStateCurrentChoice = state
+		provider = NewSacctProvider(fetcher)
+	case strings.HasPrefix(view, EXPORT_VIEW_SACCTMGR_PREFIX):
+		if !config.SacctEnabled {
+			return nil, errors.New("sacctmgr is not available on this cluster")
+		}
+		if state != config.ALL_CATEGORIES_OPTION {
+			return nil, errors.New("sacctmgr views cannot be filtered by state")
+		}
+		entity := strings.TrimPrefix(view, EXPORT_VIEW_SACCTMGR_PREFIX)
+		index := slices.IndexFunc(SACCTMGR_TABLE_ENTITIES, func(e string) bool { return strings.EqualFold(e, entity) })
+		if index < 0 {
+			return nil, fmt.Errorf("unknown sacctmgr entity '%s', must be one of '%s'", entity, strings.Join(SACCTMGR_TABLE_ENTITIES, "', '"))
+		}
+		config.SacctMgrCurrentEntity = SACCTMGR_TABLE_ENTITIES[index]
+		provider = NewSacctMgrProvider(fetcher)
+	default:
+		return nil, fmt.Errorf("unknown view '%s', must be one of '%s', '%s', '%s' or '%s<entity>'",
+			view, EXPORT_VIEW_NODES, EXPORT_VIEW_JOBS, EXPORT_VIEW_SACCT, EXPORT_VIEW_SACCTMGR_PREFIX)
+	}
+
+	if err := provider.LastError(); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", view, err)
+	}
+	return provider.FilteredData(), nil
+}
+
+// WriteTableData writes the headers and rows in the given format. Cells of combined
+// columns are written as shown in the UI, e.g. '2 / 4 / 8'.
+func WriteTableData(w io.Writer, data *TableData, format string) error {
+	var headers []string
+	for _, header := range *data.Headers {
+		headers = append(headers, header.DisplayName)
+	}
+
+	switch format {
+	case EXPORT_FORMAT_CSV, EXPORT_FORMAT_TSV:
+		writer := csv.NewWriter(w)
+		if format == EXPORT_FORMAT_TSV {
+			writer.Comma = '\t'
+		}
+		if err := writer.Write(headers); err != nil {
+			return err
+		}
+		return writer.WriteAll(data.Rows)
+	case EXPORT_FORMAT_JSON:
+		rows := make([]exportedRow, 0, len(data.Rows))
+		for _, row := range data.Rows {
+			rows = append(rows, exportedRow{headers, row})
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	case EXPORT_FORMAT_MARKDOWN:
+		separators := make([]string, len(headers))
+		for i := range separators {
+			separators[i] = "---"
+		}
+		lines := []string{markdownRow(headers), markdownRow(separators)}
+		for _, row := range data.Rows {
+			lines = append(lines, markdownRow(row))
+		}
+		_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+		return err
+	case EXPORT_FORMAT_TEXT:
+		// Aligned columns, like the table in the UI
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.Join(headers, "\t"))
+		for _, row := range data.Rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	}
+	return fmt.Errorf("unknown format '%s', must be one of '%s'", format, strings.Join(EXPORT_FORMATS, "', '"))
+}
+
+// exportedRow is a row as a JSON object, keeping the keys in column order
+type exportedRow struct {
+	headers []string
+	values  []string
+}
+
+func (r exportedRow) MarshalJSON() ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, header := range r.headers {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		key, _ := json.Marshal(header)
+		value, _ := json.Marshal(safeGet(r.values, i))
+		sb.Write(key)
+		sb.WriteString(":")
+		sb.Write(value)
+	}
+	sb.WriteString("}")
+	return []byte(sb.String()), nil
+}
+
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = strings.NewReplacer("|", "\\|", "\n", " ").Replace(cell)
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+func safeGet(values []string, index int) string {
+	if index < len(values) {
+		return values[index]
+	}
+	return ""
+}
+
+// WriteTableDataToFile writes the table to the given file in the given format, replacing any existing file
+func WriteTableDataToFile(path string, data *TableData, format string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteTableData(file, data, format); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
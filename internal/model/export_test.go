@@ -0,0 +1,113 @@
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/recorder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTableData(t *testing.T) {
+	data := &TableData{
+		Headers: &[]config.ColumnConfig{
+			{RawName: "JobId", DisplayName: "JobId"},
+			{RawName: "CPUAlloc//CPUTot", DisplayName: "CPUAlloc/CPUTot", DividedByColumn: true},
+			{RawName: "Comment", DisplayName: "Comment"},
+		},
+		Rows: [][]string{
+			{"1", "2 / 4", "a|b"},
+			{"2", "0 / 4", "with, comma"},
+		},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   EXPORT_FORMAT_CSV,
+			expected: "JobId,CPUAlloc/CPUTot,Comment\n1,2 / 4,a|b\n2,0 / 4,\"with, comma\"\n",
+		},
+		{
+			format:   EXPORT_FORMAT_TSV,
+			expected: "JobId\tCPUAlloc/CPUTot\tComment\n1\t2 / 4\ta|b\n2\t0 / 4\twith, comma\n",
+		},
+		{
+			format: EXPORT_FORMAT_JSON,
+			expected: `[
+  {
+    "JobId": "1",
+    "CPUAlloc/CPUTot": "2 / 4",
+    "Comment": "a|b"
+  },
+  {
+    "JobId": "2",
+    "CPUAlloc/CPUTot": "0 / 4",
+    "Comment": "with, comma"
+  }
+]
+`,
+		},
+		{
+			format:   EXPORT_FORMAT_MARKDOWN,
+			expected: "| JobId | CPUAlloc/CPUTot | Comment |\n| --- | --- | --- |\n| 1 | 2 / 4 | a\\|b |\n| 2 | 0 / 4 | with, comma |\n",
+		},
+		{
+			format:   EXPORT_FORMAT_TEXT,
+			expected: "JobId  CPUAlloc/CPUTot  Comment\n1      2 / 4            a|b\n2      0 / 4            with, comma\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, WriteTableData(&out, data, tt.format))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	assert.ErrorContains(t, WriteTableData(&bytes.Buffer{}, data, "xml"), "unknown format 'xml'")
+}
+
+func TestApplySearch(t *testing.T) {
+	rows := [][]string{{"1", "alice", "RUNNING"}, {"2", "bob", "PENDING"}}
+	data := &TableData{Headers: &[]config.ColumnConfig{}, Rows: rows, RowsAsSingleStrings: convertRowsToRowsAsSingleStrings(rows)}
+
+	// Matched case-insensitively, across columns
+	searched, err := data.ApplySearch("BOB.*pend")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"2", "bob", "PENDING"}}, searched.Rows)
+
+	searched, err = data.ApplySearch("")
+	require.NoError(t, err)
+	assert.Equal(t, rows, searched.Rows)
+
+	_, err = data.ApplySearch("(")
+	assert.Error(t, err)
+}
+
+func TestExportDataFiltersByState(t *testing.T) {
+	dir := t.TempDir()
+	writeRecording(t, dir, 1, "scontrol show job --detail --all --oneliner", readTestData(t, "jobs.txt"))
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+	defer func() { config.JobStateCurrentChoice = config.ALL_CATEGORIES_OPTION }()
+
+	all, err := exportData(&CliFetcher{}, EXPORT_VIEW_JOBS, "")
+	require.NoError(t, err)
+	require.NotEmpty(t, all.Rows)
+
+	running, err := exportData(&CliFetcher{}, EXPORT_VIEW_JOBS, "running")
+	require.NoError(t, err)
+	require.NotEmpty(t, running.Rows)
+	for _, row := range running.Rows {
+		assert.Equal(t, "RUNNING", row[config.JobsViewColumnsStateIndex])
+	}
+
+	_, err = exportData(&CliFetcher{}, "queue", "")
+	assert.ErrorContains(t, err, "unknown view 'queue'")
+	_, err = exportData(&CliFetcher{}, "sacctmgr:QOS", "RUNNING")
+	assert.Error(t, err)
+}
@@ -0,0 +1,285 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/logger"
+)
+
+// FederatedFetcher queries several clusters with '-M', and merges the results into one table,
+// filling in the cluster of each row in the 'Cluster' column. Data that is not specific to
+// a cluster, like sacctmgr entities, is fetched from the local cluster only.
+type FederatedFetcher struct {
+	clusters string // 'all', or a comma-separated list of cluster names
+
+	mu      sync.Mutex
+	members []*CliFetcher // One fetcher per cluster, resolved on first use
+	local   *CliFetcher
+}
+
+// NewFederatedFetcher returns a fetcher for the given clusters, either 'all' to query all
+// clusters known to slurmdbd, or a comma-separated list of cluster names.
+func NewFederatedFetcher(clusters string) *FederatedFetcher {
+	return &FederatedFetcher{clusters: clusters, local: &CliFetcher{}}
+}
+
+// clusterFetchers returns a fetcher for each cluster. With 'all', the clusters are looked
+// up from sacctmgr, and looked up again on later calls until that succeeds.
+func (f *FederatedFetcher) clusterFetchers(timeout time.Duration) ([]*CliFetcher, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.members != nil {
+		return f.members, nil
+	}
+
+	names := splitClusterNames(f.clusters)
+	if len(names) == 1 && names[0] == config.CLUSTERS_ALL {
+		var err error
+		names, err = getClusterNamesWithTimeout(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list clusters: %w", err)
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no clusters to query")
+	}
+
+	for _, name := range names {
+		f.members = append(f.members, &CliFetcher{Cluster: name})
+	}
+	logger.Printf("Federated view of clusters: %s", strings.Join(names, ", "))
+	return f.members, nil
+}
+
+func splitClusterNames(clusters string) (names []string) {
+	for _, name := range strings.Split(clusters, ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// getClusterNamesWithTimeout lists all clusters known to slurmdbd, as used by '-M all'
+func getClusterNamesWithTimeout(timeout time.Duration) ([]string, error) {
+	out, err := runSlurmCommand(slurmCommand{
+		Binary:  "sacctmgr",
+		Args:    []string{"show", "clusters", "format=Cluster", "--noheader", "--parsable2"},
+		Timeout: timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return splitClusterNames(strings.ReplaceAll(string(out), "\n", ",")), nil
+}
+
+// fetchTables fetches a table from each cluster in parallel, and merges them in the order
+// of the clusters. Clusters that fail are left out and logged, the fetch only fails if all do.
+func (f *FederatedFetcher) fetchTables(
+	columns *[]config.ColumnConfig,
+	timeout time.Duration,
+	computeColumnWidths bool,
+	fetch func(member *CliFetcher, columns *[]config.ColumnConfig) (*TableData, error),
+) (*TableData, error) {
+	members, err := f.clusterFetchers(timeout)
+	if err != nil {
+		return EmptyTableData(), err
+	}
+
+	// Each cluster gets its own copy of the columns, as column widths are updated while parsing
+	tables := make([]*TableData, len(members))
+	memberColumns := make([][]config.ColumnConfig, len(members))
+	errs := make([]error, len(members))
+	var wg sync.WaitGroup
+	for i, member := range members {
+		memberColumns[i] = slices.Clone(*columns)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tables[i], errs[i] = fetch(member, &memberColumns[i])
+		}()
+	}
+	wg.Wait()
+
+	var clusters []string
+	var succeeded []*TableData
+	for i, member := range members {
+		if errs[i] != nil {
+			logger.Printf("Failed to fetch data from cluster '%s': %v", member.Cluster, errs[i])
+			continue
+		}
+		clusters = append(clusters, member.Cluster)
+		succeeded = append(succeeded, tables[i])
+		if computeColumnWidths {
+			for j := range *columns {
+				(*columns)[j].Width = max((*columns)[j].Width, memberColumns[i][j].Width)
+			}
+		}
+	}
+	if len(succeeded) == 0 {
+		return EmptyTableData(), fmt.Errorf("cluster '%s': %w", members[0].Cluster, errs[0])
+	}
+	return mergeClusterTables(clusters, succeeded, columns, computeColumnWidths), nil
+}
+
+// mergeClusterTables concatenates the rows of tables fetched from the given clusters,
+// filling in the cluster name in any 'Cluster' column
+func mergeClusterTables(clusters []string, tables []*TableData, columns *[]config.ColumnConfig, computeColumnWidths bool) *TableData {
+	var clusterColumns []int
+	for j, col := range *columns {
+		if col.RawName == config.CLUSTER_COLUMN {
+			clusterColumns = append(clusterColumns, j)
+		}
+	}
+
+	rows := [][]string{}
+	var summaryFields []map[string]string
+	for i, table := range tables {
+		summaryFields = append(summaryFields, table.SummaryFields...)
+		for _, row := range table.Rows {
+			for _, j := range clusterColumns {
+				row[j] = clusters[i]
+			}
+			rows = append(rows, row)
+		}
+		if computeColumnWidths {
+			for _, j := range clusterColumns {
+				(*columns)[j].Width = min(max((*columns)[j].Width, len(clusters[i])), config.MaximumColumnWidth)
+			}
+		}
+	}
+
+	return &TableData{
+		Headers:             columns,
+		Rows:                rows,
+		RowsAsSingleStrings: convertRowsToRowsAsSingleStrings(rows),
+		SummaryFields:       summaryFields,
+	}
+}
+
+// onlyMatch asks all clusters, and returns the result if exactly one cluster has it. Node names
+// and job ids are not unique across clusters, so details of a row should rather be fetched
+// with a CliFetcher for the cluster in its 'Cluster' column.
+func (f *FederatedFetcher) onlyMatch(timeout time.Duration, fetch func(member *CliFetcher) (string, error)) (string, error) {
+	members, err := f.clusterFetchers(timeout)
+	if err != nil {
+		return "", err
+	}
+	var matches []string
+	var output string
+	var firstErr error
+	for _, member := range members {
+		out, err := fetch(member)
+		if err == nil {
+			matches = append(matches, member.Cluster)
+			output = out
+		} else if firstErr == nil {
+			firstErr = fmt.Errorf("cluster '%s': %w", member.Cluster, err)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", firstErr
+	case 1:
+		return output, nil
+	}
+	return "", fmt.Errorf("found on several clusters: %s", strings.Join(matches, ", "))
+}
+
+func (f *FederatedFetcher) Nodes(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	return f.fetchTables(columns, timeout, computeColumnWidths, func(member *CliFetcher, columns *[]config.ColumnConfig) (*TableData, error) {
+		return member.Nodes(columns, timeout, computeColumnWidths)
+	})
+}
+
+func (f *FederatedFetcher) Jobs(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	return f.fetchTables(columns, timeout, computeColumnWidths, func(member *CliFetcher, columns *[]config.ColumnConfig) (*TableData, error) {
+		return member.Jobs(columns, timeout, computeColumnWidths)
+	})
+}
+
+// Partitions lists the partitions of all clusters, each name only once, so that the
+// partition filter applies to partitions of the same name on all clusters.
+func (f *FederatedFetcher) Partitions(columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	data, err := f.fetchTables(columns, timeout, false, func(member *CliFetcher, columns *[]config.ColumnConfig) (*TableData, error) {
+		return member.Partitions(columns, timeout)
+	})
+	if err != nil {
+		return data, err
+	}
+
+	var names []string
+	var rows [][]string
+	for _, row := range data.Rows {
+		if len(row) > 0 && !slices.Contains(names, row[0]) {
+			names = append(names, row[0])
+			rows = append(rows, row)
+		}
+	}
+	return &TableData{
+		Headers:             data.Headers,
+		Rows:                rows,
+		RowsAsSingleStrings: convertRowsToRowsAsSingleStrings(rows),
+	}, nil
+}
+
+func (f *FederatedFetcher) Sacct(query SacctQuery, columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	return f.fetchTables(columns, timeout, computeColumnWidths, func(member *CliFetcher, columns *[]config.ColumnConfig) (*TableData, error) {
+		return member.Sacct(query, columns, timeout, computeColumnWidths)
+	})
+}
+
+func (f *FederatedFetcher) SacctMgr(entity string, columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	return f.local.SacctMgr(entity, columns, timeout)
+}
+
+func (f *FederatedFetcher) Sstat(jobIDs []string, columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	return f.local.Sstat(jobIDs, columns, timeout)
+}
+
+// Sdiag shows the scheduler status of each cluster, one after another
+func (f *FederatedFetcher) Sdiag(timeout time.Duration) (string, error) {
+	members, err := f.clusterFetchers(timeout)
+	if err != nil {
+		return "", err
+	}
+	var output strings.Builder
+	for _, member := range members {
+		out, err := member.Sdiag(timeout)
+		if err != nil {
+			out = fmt.Sprintf("Failed to fetch scheduler status: %v\n", err)
+		}
+		fmt.Fprintf(&output, "===== Cluster: %s =====\n%s\n", member.Cluster, out)
+	}
+	return output.String(), nil
+}
+
+func (f *FederatedFetcher) NodeDetails(nodeName string, timeout time.Duration) (string, error) {
+	return f.onlyMatch(timeout, func(member *CliFetcher) (string, error) {
+		return member.NodeDetails(nodeName, timeout)
+	})
+}
+
+func (f *FederatedFetcher) JobDetails(jobID string, timeout time.Duration) (string, error) {
+	return f.onlyMatch(timeout, func(member *CliFetcher) (string, error) {
+		return member.JobDetails(jobID, timeout)
+	})
+}
+
+// SacctJobDetails returns the details from the only cluster that knows the job. Unlike
+// scontrol, sacct succeeds with just the header line for unknown jobs.
+func (f *FederatedFetcher) SacctJobDetails(jobID string, timeout time.Duration) (string, error) {
+	return f.onlyMatch(timeout, func(member *CliFetcher) (string, error) {
+		out, err := member.SacctJobDetails(jobID, timeout)
+		if err == nil && !strings.Contains(strings.TrimSpace(out), "\n") {
+			return "", fmt.Errorf("job %s not found", jobID)
+		}
+		return out, err
+	})
+}
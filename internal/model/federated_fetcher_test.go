@@ -0,0 +1,107 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/recorder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFederatedFetcherMergesClusters(t *testing.T) {
+	dir := t.TempDir()
+	jobs := readTestData(t, "jobs.txt")
+	partitions := readTestData(t, "partitions.txt")
+	writeRecording(t, dir, 1, "sacctmgr show clusters format=Cluster --noheader --parsable2", "alpha\nbeta\n")
+	writeRecording(t, dir, 2, "scontrol -M alpha show job --detail --all --oneliner", jobs)
+	writeRecording(t, dir, 3, "scontrol -M beta show job --detail --all --oneliner", jobs)
+	writeRecording(t, dir, 4, "scontrol -M alpha show partitions --detail --all --oneliner", partitions)
+	writeRecording(t, dir, 5, "scontrol -M beta show partitions --detail --all --oneliner", partitions)
+	writeRecording(t, dir, 6, "scontrol -M beta show job 6833", "JobId=6833")
+	writeRecording(t, dir, 7, "scontrol -M alpha show node linux1", "NodeName=linux1")
+	writeRecording(t, dir, 8, "scontrol -M beta show node linux1", "NodeName=linux1")
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+
+	fetcher := NewFederatedFetcher(config.CLUSTERS_ALL)
+	columns := []config.ColumnConfig{
+		{RawName: "JobId", DisplayName: "JobId"},
+		{RawName: "Cluster", DisplayName: "Cluster"},
+		{RawName: "JobState", DisplayName: "JobState"},
+	}
+	single, err := (&CliFetcher{Cluster: "alpha"}).Jobs(&columns, time.Second, false)
+	require.NoError(t, err)
+
+	data, err := fetcher.Jobs(&columns, time.Second, true)
+	require.NoError(t, err)
+	require.Len(t, data.Rows, 2*len(single.Rows))
+	assert.Equal(t, "alpha", data.Rows[0][1])
+	assert.Equal(t, "beta", data.Rows[len(single.Rows)][1])
+	assert.Equal(t, len("alpha"), columns[1].Width)
+
+	// Partitions of the same name on several clusters are listed once
+	partitionColumns := []config.ColumnConfig{{RawName: "PartitionName", DisplayName: "PartitionName"}}
+	data, err = fetcher.Partitions(&partitionColumns, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 7, data.Length())
+
+	// Details of a row are fetched from its cluster
+	details, err := (&CliFetcher{Cluster: "beta"}).JobDetails("6833", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "JobId=6833", details)
+	_, err = (&CliFetcher{Cluster: "alpha"}).JobDetails("6833", time.Second)
+	assert.Error(t, err)
+
+	// Without a cluster, details are only returned if a single cluster has them
+	details, err = fetcher.JobDetails("6833", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "JobId=6833", details)
+	_, err = fetcher.NodeDetails("linux1", time.Second)
+	assert.ErrorContains(t, err, "found on several clusters: alpha, beta")
+}
+
+func TestFederatedFetcherFailsOnlyIfAllClustersFail(t *testing.T) {
+	dir := t.TempDir()
+	writeRecording(t, dir, 1, "scontrol -M alpha show node --detail --all --oneliner", "NodeName=linux1 State=IDLE")
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+
+	columns := []config.ColumnConfig{
+		{RawName: "NodeName", DisplayName: "NodeName"},
+		{RawName: "Cluster", DisplayName: "Cluster"},
+	}
+	data, err := NewFederatedFetcher("alpha, beta").Nodes(&columns, time.Second, false)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"linux1", "alpha"}}, data.Rows)
+
+	_, err = NewFederatedFetcher("beta").Nodes(&columns, time.Second, false)
+	assert.ErrorContains(t, err, "cluster 'beta'")
+}
+
+// Rows with the same id on several clusters have their own keys, e.g. for selections
+func TestRowKeyIncludesCluster(t *testing.T) {
+	data := &TableData{
+		Headers: &[]config.ColumnConfig{{RawName: "JobId"}, {RawName: "Cluster"}, {RawName: "JobState"}},
+		Rows: [][]string{
+			{"1000", "alpha", "RUNNING"},
+			{"1000", "beta", "PENDING"},
+			{"1001", "", "RUNNING"},
+		},
+	}
+	assert.Equal(t, "alpha/1000", data.RowKey(data.Rows[0]))
+	assert.Equal(t, "beta/1000", data.RowKey(data.Rows[1]))
+	assert.Equal(t, "1001", data.RowKey(data.Rows[2]))
+
+	id, row, err := data.GetRowAsMapByKey("beta/1000")
+	require.NoError(t, err)
+	assert.Equal(t, "1000", id)
+	assert.Equal(t, "PENDING", row["JobState"])
+	_, _, err = data.GetRowAsMapByKey("gamma/1000")
+	assert.Error(t, err)
+
+	// Without a 'Cluster' column, rows are identified by their first column
+	withoutCluster := &TableData{Headers: &[]config.ColumnConfig{{RawName: "JobId"}, {RawName: "JobState"}}}
+	assert.Equal(t, "1000", withoutCluster.RowKey([]string{"1000", "RUNNING"}))
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+)
+
+// Fetcher is the source of all data shown by stui. Providers are given a Fetcher on creation,
+// which allows swapping the data source, e.g. for slurmrestd or for tests using fixtures.
+type Fetcher interface {
+	// Table data
+	Nodes(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error)
+	Jobs(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error)
+	Partitions(columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error)
+	Sacct(query SacctQuery, columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error)
+	SacctMgr(entity string, columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error)
+	Sstat(jobIDs []string, columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error)
+
+	// Text data
+	Sdiag(timeout time.Duration) (string, error)
+	NodeDetails(nodeName string, timeout time.Duration) (string, error)
+	JobDetails(jobID string, timeout time.Duration) (string, error)
+	SacctJobDetails(jobID string, timeout time.Duration) (string, error)
+}
+
+// NewFetcher returns the Fetcher for the configured backend, defaulting to the Slurm CLI binaries.
+// If several clusters are configured, data from all of them is merged by a FederatedFetcher.
+func NewFetcher() Fetcher {
+	if config.Backend == config.BACKEND_SLURMRESTD {
+		return &SlurmRestdFetcher{}
+	}
+	if config.Clusters != "" {
+		return NewFederatedFetcher(config.Clusters)
+	}
+	return &CliFetcher{}
+}
+
+// CliFetcher fetches data by executing the Slurm binaries, e.g. 'scontrol', 'sacct'.
+// Table data is fetched as JSON where the Slurm version supports it, falling back to text output otherwise.
+type CliFetcher struct {
+	Cluster string // Queried with '-M' if set, otherwise the local cluster is queried
+}
+
+func (f *CliFetcher) Nodes(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	if jsonOutputSupported(f.Cluster) {
+		return getScontrolJSONDataWithTimeout(f.Cluster, clusterArgs(f.Cluster, "show", "node", "--detail", "--all"), "nodes", jsonNodeColumns, columns, timeout, computeColumnWidths)
+	}
+	return getScontrolDataWithTimeout(clusterArgs(f.Cluster, "show", "node", "--detail", "--all", "--oneliner"), columns, timeout, computeColumnWidths)
+}
+
+func (f *CliFetcher) Jobs(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	if jsonOutputSupported(f.Cluster) {
+		return getScontrolJSONDataWithTimeout(f.Cluster, clusterArgs(f.Cluster, "show", "job", "--detail", "--all"), "jobs", jsonJobColumns, columns, timeout, computeColumnWidths)
+	}
+	return getScontrolDataWithTimeout(clusterArgs(f.Cluster, "show", "job", "--detail", "--all", "--oneliner"), columns, timeout, computeColumnWidths)
+}
+
+func (f *CliFetcher) Partitions(columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	if jsonOutputSupported(f.Cluster) {
+		return getScontrolJSONDataWithTimeout(f.Cluster, clusterArgs(f.Cluster, "show", "partitions", "--detail", "--all"), "partitions", jsonPartitionColumns, columns, timeout, false)
+	}
+	return getScontrolDataWithTimeout(clusterArgs(f.Cluster, "show", "partitions", "--detail", "--all", "--oneliner"), columns, timeout, false)
+}
+
+func (f *CliFetcher) Sacct(query SacctQuery, columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	query.Cluster = f.Cluster
+	if jsonOutputSupported(f.Cluster) {
+		return getSacctJSONDataWithTimeout(query, columns, timeout, computeColumnWidths)
+	}
+	return getSacctDataWithTimeout(query, columns, timeout, computeColumnWidths)
+}
+
+func (f *CliFetcher) SacctMgr(entity string, columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	return getSacctMgrDataWithTimeout(entity, timeout, columns, false)
+}
+
+func (f *CliFetcher) Sstat(jobIDs []string, columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	return getSstatDataWithTimeout(jobIDs, columns, timeout)
+}
+
+func (f *CliFetcher) Sdiag(timeout time.Duration) (string, error) {
+	return getSdiagWithTimeout(f.Cluster, timeout)
+}
+
+func (f *CliFetcher) NodeDetails(nodeName string, timeout time.Duration) (string, error) {
+	return getNodeDetailsWithTimeout(nodeName, f.Cluster, timeout)
+}
+
+func (f *CliFetcher) JobDetails(jobID string, timeout time.Duration) (string, error) {
+	return getJobDetailsWithTimeout(jobID, f.Cluster, timeout)
+}
+
+func (f *CliFetcher) SacctJobDetails(jobID string, timeout time.Duration) (string, error) {
+	return getSacctJobDetailsWithTimeout(jobID, f.Cluster, timeout)
+}
+
+// SlurmRestdFetcher fetches data from the Slurm REST API
+type SlurmRestdFetcher struct{}
+
+func (f *SlurmRestdFetcher) Nodes(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	return getSlurmRestdNodesWithTimeout(columns, timeout, computeColumnWidths)
+}
+
+func (f *SlurmRestdFetcher) Jobs(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	return getSlurmRestdJobsWithTimeout(columns, timeout, computeColumnWidths)
+}
+
+func (f *SlurmRestdFetcher) Partitions(columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	return getSlurmRestdPartitionsWithTimeout(columns, timeout)
+}
+
+func (f *SlurmRestdFetcher) Sacct(query SacctQuery, columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	return getSlurmRestdSacctDataWithTimeout(query, columns, timeout, computeColumnWidths)
+}
+
+func (f *SlurmRestdFetcher) SacctMgr(entity string, columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	return EmptyTableData(), errSacctMgrNotSupportedBySlurmRestd
+}
+
+func (f *SlurmRestdFetcher) Sstat(jobIDs []string, columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	return EmptyTableData(), errSstatNotSupportedBySlurmRestd
+}
+
+func (f *SlurmRestdFetcher) Sdiag(timeout time.Duration) (string, error) {
+	return getSlurmRestdSdiagWithTimeout(timeout)
+}
+
+func (f *SlurmRestdFetcher) NodeDetails(nodeName string, timeout time.Duration) (string, error) {
+	return getSlurmRestdNodeDetailsWithTimeout(nodeName, timeout)
+}
+
+func (f *SlurmRestdFetcher) JobDetails(jobID string, timeout time.Duration) (string, error) {
+	return getSlurmRestdJobDetailsWithTimeout(jobID, timeout)
+}
+
+func (f *SlurmRestdFetcher) SacctJobDetails(jobID string, timeout time.Duration) (string, error) {
+	return getSlurmRestdSacctJobDetailsWithTimeout(jobID, sacctDetailFields(), timeout)
+}
@@ -0,0 +1,130 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureFetcher serves the recorded command outputs in testdata/, so providers
+// can be tested without a Slurm cluster.
+type fixtureFetcher struct {
+	t *testing.T
+}
+
+func (f *fixtureFetcher) scontrolFixture(filename string, columns *[]config.ColumnConfig, computeColumnWidths bool) (*TableData, error) {
+	return entriesToTableData(parseScontrolOutput(readTestData(f.t, filename)), columns, computeColumnWidths), nil
+}
+
+func (f *fixtureFetcher) Nodes(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	return EmptyTableData(), errors.New("no node fixture available")
+}
+
+func (f *fixtureFetcher) Jobs(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	return f.scontrolFixture("jobs.txt", columns, computeColumnWidths)
+}
+
+func (f *fixtureFetcher) Partitions(columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	return f.scontrolFixture("partitions.txt", columns, false)
+}
+
+func (f *fixtureFetcher) Sacct(query SacctQuery, columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	return EmptyTableData(), nil
+}
+
+func (f *fixtureFetcher) SacctMgr(entity string, columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	if entity != SACCT_RUNAWAYJOBS_ENTITY {
+		return EmptyTableData(), errors.New("no fixture for entity " + entity)
+	}
+	return entriesToTableData(parseSacctMgrRunawayJobsOutput(readTestData(f.t, "runaway_jobs.txt")), columns, false), nil
+}
+
+func (f *fixtureFetcher) Sstat(jobIDs []string, columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	return entriesToTableData(parseSacctOutput(readTestData(f.t, "sstat.txt")), columns, false), nil
+}
+
+func (f *fixtureFetcher) Sdiag(timeout time.Duration) (string, error) {
+	return "", errors.New("no sdiag fixture available")
+}
+
+func (f *fixtureFetcher) NodeDetails(nodeName string, timeout time.Duration) (string, error) {
+	return "", errors.New("no node fixture available")
+}
+
+func (f *fixtureFetcher) JobDetails(jobID string, timeout time.Duration) (string, error) {
+	return "", errors.New("no job details fixture available")
+}
+
+func (f *fixtureFetcher) SacctJobDetails(jobID string, timeout time.Duration) (string, error) {
+	return "", errors.New("no sacct fixture available")
+}
+
+func TestJobsProviderWithFixtures(t *testing.T) {
+	provider := NewJobsProvider(&fixtureFetcher{t: t})
+	require.NoError(t, provider.LastError())
+	assert.Equal(t, 630, provider.Length())
+
+	tests := []struct {
+		name            string
+		partitionFilter string
+		stateFilter     string
+		expectedCount   int
+	}{
+		{"no filters", config.ALL_CATEGORIES_OPTION, config.ALL_CATEGORIES_OPTION, 630},
+		{"physics partition", "physics", config.ALL_CATEGORIES_OPTION, 90},
+		{"physics partition and running", "physics", "RUNNING", 90},
+		{"pending only", config.ALL_CATEGORIES_OPTION, "PENDING", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.PartitionFilter = tt.partitionFilter
+			config.JobStateCurrentChoice = tt.stateFilter
+			defer func() {
+				config.PartitionFilter = config.ALL_CATEGORIES_OPTION
+				config.JobStateCurrentChoice = config.ALL_CATEGORIES_OPTION
+			}()
+
+			data := provider.FilteredData()
+			assert.Len(t, data.Rows, tt.expectedCount)
+			assert.Equal(t, *config.JobViewColumns, *data.Headers)
+		})
+	}
+}
+
+func TestPartitionsProviderWithFixtures(t *testing.T) {
+	data := NewPartitionsProvider(&fixtureFetcher{t: t}).FilteredData()
+	require.Len(t, data.Rows, 7)
+	assert.Equal(t, "general", data.Rows[0][0])
+	assert.Equal(t, "unallocated", data.Rows[6][0])
+}
+
+func TestSacctMgrProviderWithFixtures(t *testing.T) {
+	previousEntity := config.SacctMgrCurrentEntity
+	defer func() { config.SacctMgrCurrentEntity = previousEntity }()
+
+	config.SacctMgrCurrentEntity = SACCT_RUNAWAYJOBS_ENTITY
+	provider := NewSacctMgrProvider(&fixtureFetcher{t: t})
+	require.NoError(t, provider.LastError())
+
+	data := provider.FilteredData()
+	require.NotEmpty(t, data.Rows)
+	row, err := data.GetRowAsMapById("3")
+	require.NoError(t, err)
+	assert.Equal(t, "job-physics-1", row["Name"])
+	assert.Equal(t, "PENDING", row["State"])
+
+	config.SacctMgrCurrentEntity = "Account"
+	assert.Error(t, provider.Fetch())
+	assert.Error(t, provider.LastError())
+}
+
+func TestProviderKeepsErrorFromFetcher(t *testing.T) {
+	provider := NewNodesProvider(&fixtureFetcher{t: t})
+	assert.EqualError(t, provider.LastError(), "no node fixture available")
+	assert.Equal(t, 0, provider.Length())
+}
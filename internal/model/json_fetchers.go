@@ -0,0 +1,134 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/logger"
+)
+
+// JSON output of scontrol and sacct is available from Slurm 21.08 onward. It avoids the
+// problems of scraping the text output, e.g. values with spaces being truncated.
+const (
+	JSON_OUTPUT_MINIMUM_SLURM_MAJOR_VERSION = 21
+	JSON_OUTPUT_MINIMUM_SLURM_MINOR_VERSION = 8
+)
+
+// Errors of Slurm builds that don't support '--json', e.g. without a JSON serializer plugin.
+// Other errors, e.g. a job that no longer exists, don't change the output used.
+var jsonUnsupportedMarkers = []string{
+	"unrecognized option",
+	"invalid option",
+	"unknown option",
+	"serializer",
+}
+
+// jsonOutputSupported returns true if JSON output should be used for scontrol and sacct on the
+// given cluster, or the local cluster if empty
+func jsonOutputSupported(cluster string) bool {
+	if config.JSONOutputUnavailable(cluster) {
+		return false
+	}
+	return slurmVersionAtLeast(
+		config.SchedulerSlurmVersion,
+		JSON_OUTPUT_MINIMUM_SLURM_MAJOR_VERSION,
+		JSON_OUTPUT_MINIMUM_SLURM_MINOR_VERSION,
+	)
+}
+
+// jsonOutputUnsupported returns true if a '--json' command failed because JSON output is not
+// supported. Only stderr is checked, as the output has e.g. job names and comments that may well
+// contain the same words.
+func jsonOutputUnsupported(err error) bool {
+	var commandErr *CommandError
+	if !errors.As(err, &commandErr) || commandErr.Kind != ErrCommandFailed {
+		return false
+	}
+	stderr := strings.ToLower(commandErr.Stderr)
+	for _, marker := range jsonUnsupportedMarkers {
+		if strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// slurmVersionAtLeast compares a Slurm version string, e.g. '23.11.4', against a major and minor version
+func slurmVersionAtLeast(version string, major, minor int) bool {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	if len(parts) < 2 {
+		return false
+	}
+	versionMajor, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	versionMinor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return versionMajor > major || (versionMajor == major && versionMinor >= minor)
+}
+
+// disableJSONOutput makes all further fetches from the cluster use the text output, e.g. if it
+// doesn't support JSON output, or its output can't be parsed
+func disableJSONOutput(cluster string, command slurmCommand, err error) {
+	if config.DisableJSONOutput(cluster) {
+		logger.Printf("JSON output not usable, falling back to text output: %s (%v)", command, err)
+	}
+}
+
+func getScontrolJSONDataWithTimeout(cluster string, args []string, listKey string, jsonColumns []jsonColumn, columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	command := slurmCommand{
+		Binary:  "scontrol",
+		Args:    append([]string{"--json"}, args...),
+		Timeout: timeout,
+	}
+	textArgs := append(append([]string{}, args...), "--oneliner")
+
+	out, err := runSlurmCommand(command)
+	if jsonOutputUnsupported(err) {
+		disableJSONOutput(cluster, command, err)
+		return getScontrolDataWithTimeout(textArgs, columns, timeout, computeColumnWidths)
+	}
+	if err != nil {
+		return EmptyTableData(), err
+	}
+
+	entries, err := parseJSONOutput(out, listKey, jsonColumns)
+	if err != nil {
+		// Remembered like unsupported output, so that each refresh doesn't run both commands
+		disableJSONOutput(cluster, command, err)
+		return getScontrolDataWithTimeout(textArgs, columns, timeout, computeColumnWidths)
+	}
+	return entriesToTableData(entries, columns, computeColumnWidths), nil
+}
+
+func getSacctJSONDataWithTimeout(query SacctQuery, columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	// The output fields can't be selected with '--json', all are always included
+	command := slurmCommand{
+		Binary:  "sacct",
+		Args:    append([]string{"--json", "--allocations"}, query.args()...),
+		Timeout: timeout,
+	}
+
+	out, err := runSlurmCommand(command)
+	if jsonOutputUnsupported(err) {
+		disableJSONOutput(query.Cluster, command, err)
+		return getSacctDataWithTimeout(query, columns, timeout, computeColumnWidths)
+	}
+	if err != nil {
+		return EmptyTableData(), err
+	}
+
+	entries, err := parseJSONOutput(out, "jobs", jsonSacctColumns)
+	if err != nil {
+		// Remembered like unsupported output, so that each refresh doesn't run both commands
+		disableJSONOutput(query.Cluster, command, err)
+		return getSacctDataWithTimeout(query, columns, timeout, computeColumnWidths)
+	}
+	return entriesToTableData(entries, columns, computeColumnWidths), nil
+}
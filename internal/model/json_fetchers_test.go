@@ -0,0 +1,187 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/recorder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlurmVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"23.11.4", true},
+		{"21.08.0", true},
+		{"21.8", true},
+		{"20.11.9", false},
+		{"21.07", false},
+		{"unknown", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.expected, slurmVersionAtLeast(tt.version, 21, 8))
+		})
+	}
+}
+
+// useSlurmVersion sets the detected Slurm version for the duration of the test
+func useSlurmVersion(t *testing.T, version string) {
+	previousVersion := config.SchedulerSlurmVersion
+	config.SchedulerSlurmVersion = version
+	config.ResetJSONOutput()
+	t.Cleanup(func() {
+		config.SchedulerSlurmVersion = previousVersion
+		config.ResetJSONOutput()
+	})
+}
+
+func TestCliFetcherUsesJSONOutput(t *testing.T) {
+	useSlurmVersion(t, "23.11.4")
+	dir := t.TempDir()
+	writeRecording(t, dir, 1, "scontrol --json show job --detail --all", readTestData(t, "slurmrestd/jobs.json"))
+	writeRecording(t, dir, 2, "sacct --json --allocations --allusers --starttime=now-60", readTestData(t, "slurmrestd/slurmdb_jobs.json"))
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+
+	columns := []config.ColumnConfig{
+		{RawName: "JobId", DisplayName: "JobId"},
+		{RawName: "Command", DisplayName: "Command"},
+		{RawName: "JobState", DisplayName: "JobState"},
+	}
+	data, err := (&CliFetcher{}).Jobs(&columns, time.Second, false)
+	require.NoError(t, err)
+	require.Len(t, data.Rows, 2)
+	// Values with spaces are kept whole, unlike with the text output
+	assert.Equal(t, []string{"6833", "/home/johndoe/dev/stui/testing/sleep.sh --seconds 3600", "RUNNING"}, data.Rows[0])
+
+	previousRefreshInterval := config.RefreshInterval
+	config.RefreshInterval = 60 * time.Second
+	defer func() { config.RefreshInterval = previousRefreshInterval }()
+
+	sacctColumns := []config.ColumnConfig{
+		{RawName: "JobIDRaw", DisplayName: "JobIDRaw"},
+		{RawName: "State", DisplayName: "State"},
+	}
+	data, err = (&CliFetcher{}).Sacct(SacctQuery{}, &sacctColumns, time.Second, false)
+	require.NoError(t, err)
+	require.Len(t, data.Rows, 2)
+	assert.Equal(t, []string{"6700", "COMPLETED"}, data.Rows[0])
+}
+
+// writeFailedRecording stores a failed run of the given command, as recorded by runSlurmCommand
+func writeFailedRecording(t *testing.T, dir string, index int, command, stderr string) {
+	commandErr := &CommandError{Kind: ErrCommandFailed, Binary: "scontrol", ExitCode: 1, Stderr: stderr}
+	raw, err := json.Marshal(recorder.Recording{
+		Timestamp: time.Now(),
+		Command:   command,
+		Error:     commandErr.Error(),
+		ErrorKind: "failed",
+		ExitCode:  commandErr.ExitCode,
+		Stderr:    commandErr.Stderr,
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%04d.json", index)), raw, 0o644))
+}
+
+func TestCliFetcherFallsBackToTextOutput(t *testing.T) {
+	useSlurmVersion(t, "23.11.4")
+	dir := t.TempDir()
+	writeFailedRecording(t, dir, 1, "scontrol --json show partitions --detail --all", "scontrol: error: No serializer available")
+	writeRecording(t, dir, 2, "scontrol show partitions --detail --all --oneliner", readTestData(t, "partitions.txt"))
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+
+	data, err := (&CliFetcher{}).Partitions(&[]config.ColumnConfig{{RawName: "PartitionName", DisplayName: "PartitionName"}}, time.Second)
+	require.NoError(t, err)
+	assert.Len(t, data.Rows, 7)
+	assert.False(t, jsonOutputSupported(""))
+	// Other clusters still use JSON output
+	assert.True(t, jsonOutputSupported("other"))
+}
+
+func TestCliFetcherKeepsJSONOutputOnCommandErrors(t *testing.T) {
+	useSlurmVersion(t, "23.11.4")
+	dir := t.TempDir()
+	writeFailedRecording(t, dir, 1, "scontrol --json show partitions --detail --all", "slurm_load_partitions error: Unable to contact slurm controller")
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+
+	_, err := (&CliFetcher{}).Partitions(&[]config.ColumnConfig{{RawName: "PartitionName", DisplayName: "PartitionName"}}, time.Second)
+	assert.ErrorContains(t, err, "Unable to contact slurm controller")
+	assert.True(t, jsonOutputSupported(""))
+}
+
+func TestJSONOutputUnsupported(t *testing.T) {
+	failed := func(stderr string) error {
+		return &CommandError{Kind: ErrCommandFailed, Binary: "scontrol", ExitCode: 1, Stderr: stderr}
+	}
+	assert.True(t, jsonOutputUnsupported(failed("scontrol: unrecognized option '--json'")))
+	assert.True(t, jsonOutputUnsupported(failed("scontrol: error: No serializer available")))
+	assert.False(t, jsonOutputUnsupported(failed("Invalid job id specified")))
+	assert.False(t, jsonOutputUnsupported(&CommandError{Kind: ErrCommandTimeout, Stderr: "invalid option"}))
+	// Only the stderr of failed commands is checked, not messages of other errors
+	assert.False(t, jsonOutputUnsupported(errors.New("scontrol: unrecognized option '--json'")))
+	assert.False(t, jsonOutputUnsupported(nil))
+}
+
+// Successful output is never taken as JSON output being unsupported, even if it has the same words
+func TestCliFetcherKeepsJSONOutputForValuesLikeErrors(t *testing.T) {
+	useSlurmVersion(t, "23.11.4")
+	dir := t.TempDir()
+	writeRecording(t, dir, 1, "scontrol --json show job --detail --all",
+		`{"jobs": [{"job_id": 1, "name": "test", "job_state": ["RUNNING"], "comment": "retry with invalid option --serializer"}]}`)
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+
+	columns := []config.ColumnConfig{{RawName: "JobId", DisplayName: "JobId"}, {RawName: "Comment", DisplayName: "Comment"}}
+	data, err := (&CliFetcher{}).Jobs(&columns, time.Second, false)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"1", "retry with invalid option --serializer"}}, data.Rows)
+	assert.True(t, jsonOutputSupported(""))
+}
+
+// Output that can't be parsed falls back to the text output once, and the text output is used from then on
+func TestCliFetcherRemembersUnparseableJSONOutput(t *testing.T) {
+	useSlurmVersion(t, "23.11.4")
+	dir := t.TempDir()
+	writeRecording(t, dir, 1, "scontrol --json show partitions --detail --all", `{"partitions": [`)
+	writeRecording(t, dir, 2, "scontrol show partitions --detail --all --oneliner", readTestData(t, "partitions.txt"))
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+
+	columns := []config.ColumnConfig{{RawName: "PartitionName", DisplayName: "PartitionName"}}
+	data, err := (&CliFetcher{}).Partitions(&columns, time.Second)
+	require.NoError(t, err)
+	assert.Len(t, data.Rows, 7)
+	assert.False(t, jsonOutputSupported(""))
+
+	before := FetchCounter.Count
+	data, err = (&CliFetcher{}).Partitions(&columns, time.Second)
+	require.NoError(t, err)
+	assert.Len(t, data.Rows, 7)
+	assert.Equal(t, 1, FetchCounter.Count-before, "only the text command is run")
+}
+
+func TestCliFetcherUsesTextOutputOnOldSlurm(t *testing.T) {
+	useSlurmVersion(t, "20.11.9")
+	dir := t.TempDir()
+	writeRecording(t, dir, 1, "scontrol show partitions --detail --all --oneliner", readTestData(t, "partitions.txt"))
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+
+	data, err := (&CliFetcher{}).Partitions(&[]config.ColumnConfig{{RawName: "PartitionName", DisplayName: "PartitionName"}}, time.Second)
+	require.NoError(t, err)
+	assert.Len(t, data.Rows, 7)
+}
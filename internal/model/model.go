@@ -2,8 +2,11 @@ package model
 
 import (
 	"errors"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/antvirf/stui/internal/config"
 )
@@ -13,6 +16,8 @@ type TableData struct {
 	Headers             *[]config.ColumnConfig
 	Rows                [][]string // List of lists
 	RowsAsSingleStrings []string   // List of strings - used for searching
+	// Values of SUMMARY_FIELDS for each row, whether or not they are shown. Only set for fetched data.
+	SummaryFields []map[string]string
 }
 
 func EmptyTableData() *TableData {
@@ -31,6 +36,50 @@ func convertRowsToRowsAsSingleStrings(rows [][]string) []string {
 	return rowsAsStrings
 }
 
+// entriesToTableData converts parsed entries into table rows, following the given column config.
+// Combined columns ('//') are joined together, and column widths are optionally computed from the data.
+func entriesToTableData(entries []map[string]string, columns *[]config.ColumnConfig, computeColumnWidths bool) *TableData {
+	var rows [][]string
+	var summaryFields []map[string]string
+	for _, entry := range entries {
+		summaryFields = append(summaryFields, entrySummaryFields(entry))
+		row := make([]string, len(*columns))
+		for j := range *columns {
+			// Access elements by index so we modify the original
+			col := &(*columns)[j]
+
+			if computeColumnWidths {
+				col.Width = min(
+					max( // Increase col width if current cell is bigger than current max
+						len(safeGetFromMap(entry, col.DisplayName)),
+						col.Width,
+					),
+					config.MaximumColumnWidth, // .. but don't go above this value.
+				)
+			}
+
+			if col.DividedByColumn {
+				components := strings.Split(col.RawName, "//")
+				var values []string
+				for _, component := range components {
+					values = append(values, safeGetFromMap(entry, component))
+				}
+				row[j] = strings.Join(values, " / ")
+			} else {
+				row[j] = safeGetFromMap(entry, col.DisplayName)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return &TableData{
+		Headers:             columns,
+		Rows:                rows,
+		RowsAsSingleStrings: convertRowsToRowsAsSingleStrings(rows),
+		SummaryFields:       summaryFields,
+	}
+}
+
 // DeepCopy creates a deep copy of the TableData struct.
 func (t *TableData) DeepCopy() *TableData {
 	var copiedHeaders *[]config.ColumnConfig
@@ -51,6 +100,7 @@ func (t *TableData) DeepCopy() *TableData {
 		Headers:             copiedHeaders,
 		Rows:                rowsCopy,
 		RowsAsSingleStrings: convertRowsToRowsAsSingleStrings(rowsCopy),
+		SummaryFields:       slices.Clone(t.SummaryFields), // Not modified once fetched
 	}
 }
 
@@ -90,9 +140,10 @@ func (c *threadSafeCounter) increment() {
 func init() {
 	FetchCounter = threadSafeCounter{Count: 0}
 
-	// We increment count here, because we do a call during
-	// config initialization.
-	FetchCounter.increment()
+	// The checks during config initialization run through the same runner, and are counted
+	config.RunSlurmCommand = func(binary string, args []string, timeout time.Duration) ([]byte, error) {
+		return runSlurmCommand(slurmCommand{Binary: binary, Args: args, Timeout: timeout})
+	}
 }
 
 // Applies a list of given filters to the data
@@ -100,8 +151,9 @@ func (t *TableData) ApplyFilters(filters map[int]string) *TableData {
 	data := t.DeepCopy()
 
 	var rows [][]string
+	var summaryFields []map[string]string
 rowLoop:
-	for _, row := range data.Rows {
+	for i, row := range data.Rows {
 		for filterKey, filterValue := range filters {
 			if filterValue != config.ALL_CATEGORIES_OPTION {
 				if !strings.Contains(row[filterKey], filterValue) {
@@ -110,13 +162,47 @@ rowLoop:
 			}
 		}
 		rows = append(rows, row)
+		if len(data.SummaryFields) == len(data.Rows) {
+			summaryFields = append(summaryFields, data.SummaryFields[i])
+		}
 	}
 
 	return &TableData{
 		Headers:             data.Headers,
 		Rows:                rows,
 		RowsAsSingleStrings: convertRowsToRowsAsSingleStrings(rows),
+		SummaryFields:       summaryFields,
+	}
+}
+
+// ApplySearch keeps the rows matching the given regex, case-insensitively. Each row is matched
+// as a single string, which allows for regexes across columns.
+func (t *TableData) ApplySearch(pattern string) (*TableData, error) {
+	if pattern == "" {
+		return t, nil
 	}
+	regex, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return t, err
+	}
+
+	// Preallocate slice with reasonable capacity
+	rows := make([][]string, 0, len(t.Rows)/2)
+	var summaryFields []map[string]string
+	for i, row := range t.Rows {
+		if regex.MatchString(t.RowsAsSingleStrings[i]) {
+			rows = append(rows, row)
+			if len(t.SummaryFields) == len(t.Rows) {
+				summaryFields = append(summaryFields, t.SummaryFields[i])
+			}
+		}
+	}
+	return &TableData{
+		Headers:             t.Headers,
+		Rows:                rows,
+		RowsAsSingleStrings: convertRowsToRowsAsSingleStrings(rows),
+		SummaryFields:       summaryFields,
+	}, nil
 }
 
 func (td *TableData) rowToMap(row []string) map[string]string {
@@ -139,3 +225,27 @@ func (td *TableData) GetRowAsMapById(idString string) (map[string]string, error)
 	}
 	return nil, errors.New("not found")
 }
+
+// RowKey identifies a row in selections. Node names and job ids are not unique across clusters,
+// so rows with a value in a 'Cluster' column are identified by their cluster and first column.
+func (td *TableData) RowKey(row []string) string {
+	if len(row) == 0 {
+		return ""
+	}
+	for i, header := range *td.Headers {
+		if header.RawName == config.CLUSTER_COLUMN && i < len(row) && row[i] != "" {
+			return row[i] + "/" + row[0]
+		}
+	}
+	return row[0]
+}
+
+// GetRowAsMapByKey returns the first column and the fields of the row with the given key, see RowKey
+func (td *TableData) GetRowAsMapByKey(key string) (string, map[string]string, error) {
+	for _, row := range td.Rows {
+		if len(row) > 0 && td.RowKey(row) == key {
+			return row[0], td.rowToMap(row), nil
+		}
+	}
+	return "", nil, errors.New("not found")
+}
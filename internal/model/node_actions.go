@@ -0,0 +1,116 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Actions of the node action form, each producing a single 'scontrol' command
+const (
+	NODE_ACTION_DRAIN        = "Drain"
+	NODE_ACTION_RESUME       = "Resume"
+	NODE_ACTION_DOWN         = "Down"
+	NODE_ACTION_UNDRAIN      = "Undrain"
+	NODE_ACTION_REBOOT       = "Reboot ASAP"
+	NODE_ACTION_SET_FEATURES = "Set features"
+	NODE_ACTION_SET_WEIGHT   = "Set weight"
+)
+
+var NODE_ACTIONS = []string{
+	NODE_ACTION_DRAIN,
+	NODE_ACTION_RESUME,
+	NODE_ACTION_DOWN,
+	NODE_ACTION_UNDRAIN,
+	NODE_ACTION_REBOOT,
+	NODE_ACTION_SET_FEATURES,
+	NODE_ACTION_SET_WEIGHT,
+}
+
+// States a node can be put into after 'scontrol reboot', see 'nextstate' in 'man scontrol'
+var NODE_REBOOT_NEXT_STATES = []string{"RESUME", "DOWN"}
+
+// NodeAction is the input of the node action form. Fields that don't apply to the action are ignored.
+type NodeAction struct {
+	Action    string // One of NODE_ACTIONS
+	Reason    string // Required for drain and down, optional for reboot
+	Reboot    bool   // Reboot drained nodes once they are idle, leaving them down afterwards
+	NextState string // One of NODE_REBOOT_NEXT_STATES
+	Features  string // Comma-separated list of features
+	Weight    string
+}
+
+var nodeFeaturesPattern = regexp.MustCompile(`^[\w.:+-]+(,[\w.:+-]+)*$`)
+
+// NodeActionCommand validates the action and returns the 'scontrol' command that applies it to
+// the given nodes, quoted so that it can be run with a shell
+func NodeActionCommand(nodes []string, action NodeAction) (string, error) {
+	if len(nodes) == 0 {
+		return "", fmt.Errorf("no nodes selected")
+	}
+	nodeList := strings.Join(nodes, ",")
+	scontrol := slurmCommand{Binary: "scontrol"}.path()
+	update := fmt.Sprintf("%s update NodeName=%s", scontrol, nodeList)
+
+	reason := strings.TrimSpace(action.Reason)
+	if strings.ContainsAny(reason, "\n\r") {
+		return "", fmt.Errorf("reason must be a single line")
+	}
+
+	switch action.Action {
+	case NODE_ACTION_DRAIN:
+		if reason == "" {
+			return "", fmt.Errorf("a reason is required to drain nodes")
+		}
+		if action.Reboot {
+			// Rebooting ASAP drains the nodes right away, with the reason shown until they are back
+			return fmt.Sprintf("%s reboot ASAP nextstate=DOWN reason=%s %s", scontrol, ShellQuote(reason), nodeList), nil
+		}
+		return fmt.Sprintf("%s State=DRAIN Reason=%s", update, ShellQuote(reason)), nil
+
+	case NODE_ACTION_DOWN:
+		if reason == "" {
+			return "", fmt.Errorf("a reason is required to set nodes down")
+		}
+		return fmt.Sprintf("%s State=DOWN Reason=%s", update, ShellQuote(reason)), nil
+
+	case NODE_ACTION_RESUME:
+		return update + " State=RESUME", nil
+
+	case NODE_ACTION_UNDRAIN:
+		return update + " State=UNDRAIN", nil
+
+	case NODE_ACTION_REBOOT:
+		nextState := strings.ToUpper(action.NextState)
+		if !slices.Contains(NODE_REBOOT_NEXT_STATES, nextState) {
+			return "", fmt.Errorf("next state must be one of %s", strings.Join(NODE_REBOOT_NEXT_STATES, ", "))
+		}
+		command := fmt.Sprintf("%s reboot ASAP nextstate=%s", scontrol, nextState)
+		if reason != "" {
+			command += " reason=" + ShellQuote(reason)
+		}
+		return command + " " + nodeList, nil
+
+	case NODE_ACTION_SET_FEATURES:
+		features := strings.ReplaceAll(action.Features, " ", "")
+		if !nodeFeaturesPattern.MatchString(features) {
+			return "", fmt.Errorf("features must be a comma-separated list like 'gpu,ib', got '%s'", action.Features)
+		}
+		return fmt.Sprintf("%s AvailableFeatures=%s", update, features), nil
+
+	case NODE_ACTION_SET_WEIGHT:
+		weight, err := strconv.ParseUint(strings.TrimSpace(action.Weight), 10, 32)
+		if err != nil {
+			return "", fmt.Errorf("weight must be a whole number between 0 and %d, got '%s'", uint32(1<<32-1), action.Weight)
+		}
+		return fmt.Sprintf("%s Weight=%d", update, weight), nil
+	}
+	return "", fmt.Errorf("unknown node action '%s'", action.Action)
+}
+
+// ShellQuote quotes the text as a single shell word, e.g. for values in plugin and node action commands
+func ShellQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", `'\''`) + "'"
+}
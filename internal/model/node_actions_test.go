@@ -0,0 +1,117 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeActionCommand(t *testing.T) {
+	previousPath := config.SlurmBinariesPath
+	defer func() { config.SlurmBinariesPath = previousPath }()
+	config.SlurmBinariesPath = ""
+
+	nodes := []string{"node1", "node2"}
+	tests := []struct {
+		name    string
+		action  NodeAction
+		command string
+		err     string
+	}{
+		{
+			name:    "drain",
+			action:  NodeAction{Action: NODE_ACTION_DRAIN, Reason: " bad DIMM, see ticket 'HW-1' "},
+			command: `scontrol update NodeName=node1,node2 State=DRAIN Reason='bad DIMM, see ticket '\''HW-1'\'''`,
+		},
+		{
+			name:    "drain and reboot",
+			action:  NodeAction{Action: NODE_ACTION_DRAIN, Reason: "kernel update", Reboot: true},
+			command: "scontrol reboot ASAP nextstate=DOWN reason='kernel update' node1,node2",
+		},
+		{
+			name:   "drain without reason",
+			action: NodeAction{Action: NODE_ACTION_DRAIN, Reason: "  "},
+			err:    "a reason is required to drain nodes",
+		},
+		{
+			name:   "reason with newline",
+			action: NodeAction{Action: NODE_ACTION_DOWN, Reason: "first\nsecond"},
+			err:    "reason must be a single line",
+		},
+		{
+			name:    "down",
+			action:  NodeAction{Action: NODE_ACTION_DOWN, Reason: "dead"},
+			command: "scontrol update NodeName=node1,node2 State=DOWN Reason='dead'",
+		},
+		{
+			name:    "resume ignores reason",
+			action:  NodeAction{Action: NODE_ACTION_RESUME, Reason: "ignored"},
+			command: "scontrol update NodeName=node1,node2 State=RESUME",
+		},
+		{
+			name:    "undrain",
+			action:  NodeAction{Action: NODE_ACTION_UNDRAIN},
+			command: "scontrol update NodeName=node1,node2 State=UNDRAIN",
+		},
+		{
+			name:    "reboot",
+			action:  NodeAction{Action: NODE_ACTION_REBOOT, NextState: "resume"},
+			command: "scontrol reboot ASAP nextstate=RESUME node1,node2",
+		},
+		{
+			name:    "reboot with reason",
+			action:  NodeAction{Action: NODE_ACTION_REBOOT, NextState: "DOWN", Reason: "firmware"},
+			command: "scontrol reboot ASAP nextstate=DOWN reason='firmware' node1,node2",
+		},
+		{
+			name:   "reboot with unknown next state",
+			action: NodeAction{Action: NODE_ACTION_REBOOT, NextState: "IDLE"},
+			err:    "next state must be one of RESUME, DOWN",
+		},
+		{
+			name:    "features",
+			action:  NodeAction{Action: NODE_ACTION_SET_FEATURES, Features: "gpu, ib,a100"},
+			command: "scontrol update NodeName=node1,node2 AvailableFeatures=gpu,ib,a100",
+		},
+		{
+			name:   "features with shell characters",
+			action: NodeAction{Action: NODE_ACTION_SET_FEATURES, Features: "gpu;reboot"},
+			err:    "features must be a comma-separated list",
+		},
+		{
+			name:    "weight",
+			action:  NodeAction{Action: NODE_ACTION_SET_WEIGHT, Weight: " 100 "},
+			command: "scontrol update NodeName=node1,node2 Weight=100",
+		},
+		{
+			name:   "negative weight",
+			action: NodeAction{Action: NODE_ACTION_SET_WEIGHT, Weight: "-1"},
+			err:    "weight must be a whole number between 0 and 4294967295",
+		},
+		{
+			name:   "unknown action",
+			action: NodeAction{Action: "Explode"},
+			err:    "unknown node action 'Explode'",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			command, err := NodeActionCommand(nodes, test.action)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.command, command)
+		})
+	}
+
+	_, err := NodeActionCommand(nil, NodeAction{Action: NODE_ACTION_RESUME})
+	assert.ErrorContains(t, err, "no nodes selected")
+
+	config.SlurmBinariesPath = "/opt/slurm/bin"
+	command, err := NodeActionCommand([]string{"node1"}, NodeAction{Action: NODE_ACTION_RESUME})
+	assert.NoError(t, err)
+	assert.Equal(t, "/opt/slurm/bin/scontrol update NodeName=node1 State=RESUME", command)
+}
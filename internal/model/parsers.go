@@ -2,11 +2,8 @@ package model
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
-
-	"github.com/antvirf/stui/internal/logger"
 )
 
 // safeGetFromMap retrieves a value from a map by key, returning an empty string if the key does not exist
@@ -119,12 +116,6 @@ func parseSacctOutput(output string) (entries []map[string]string) {
 
 // parseSacctMgrRunawayJobsOutput parses the sacctmgr runaway jobs format into a slice of maps
 func parseSacctMgrRunawayJobsOutput(output string) (entries []map[string]string) {
-	if os.Getenv("STUI_TESTING") != "" {
-		logger.Debugf("STUI_TESTING env var set, using hardcoded runaway jobs data...")
-		rawOut, _ := os.ReadFile("./internal/model/testdata/runaway_jobs.txt")
-		output = string(rawOut)
-	}
-
 	lines := strings.Split(output, "\n")
 	headers := []string{}
 	for _, line := range lines {
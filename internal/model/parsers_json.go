@@ -0,0 +1,529 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jsonColumn maps a column name, as used by the scontrol/sacct text output and
+// therefore by `ColumnConfig`, to a value derived from a flattened JSON entry.
+type jsonColumn struct {
+	Name  string
+	Value func(flat map[string]string) string
+}
+
+// Mappings for the JSON data returned by slurmrestd, as well as `scontrol --json` and `sacct --json`,
+// which share the same data_parser schema. Ordering is used for the detail views.
+var (
+	jsonNodeColumns = []jsonColumn{
+		{"NodeName", jsonField("name")},
+		{"Arch", jsonField("architecture")},
+		{"CoresPerSocket", jsonField("cores")},
+		{"CPUAlloc", jsonField("alloc_cpus")},
+		{"CPUEfctv", jsonField("effective_cpus")},
+		{"CPUTot", jsonField("cpus")},
+		{"CPULoad", jsonCPULoad("cpu_load")},
+		{"AvailableFeatures", jsonField("features")},
+		{"ActiveFeatures", jsonField("active_features")},
+		{"Gres", jsonField("gres")},
+		{"GresDrain", jsonField("gres_drained")},
+		{"GresUsed", jsonField("gres_used")},
+		{"NodeAddr", jsonField("address")},
+		{"NodeHostName", jsonField("hostname")},
+		{"Port", jsonField("port")},
+		{"Version", jsonField("version")},
+		{"OS", jsonField("operating_system")},
+		{"RealMemory", jsonField("real_memory")},
+		{"AllocMem", jsonField("alloc_memory")},
+		{"FreeMem", jsonField("free_mem")},
+		{"Sockets", jsonField("sockets")},
+		{"Boards", jsonField("boards")},
+		{"State", jsonState("state")},
+		{"ThreadsPerCore", jsonField("threads")},
+		{"TmpDisk", jsonField("temporary_disk")},
+		{"Weight", jsonField("weight")},
+		{"Owner", jsonField("owner")},
+		{"MCS_label", jsonField("mcs_label")},
+		{"Partitions", jsonField("partitions")},
+		{"BootTime", jsonTimestamp("boot_time")},
+		{"SlurmdStartTime", jsonTimestamp("slurmd_start_time")},
+		{"LastBusyTime", jsonTimestamp("last_busy")},
+		{"ResumeAfterTime", jsonTimestamp("resume_after")},
+		{"CfgTRES", jsonField("tres")},
+		{"AllocTRES", jsonField("tres_used")},
+		{"CurrentWatts", jsonField("energy.current_watts")},
+		{"AveWatts", jsonField("energy.average_watts")},
+		{"Comment", jsonField("comment")},
+		{"Reason", jsonField("reason")},
+	}
+
+	jsonJobColumns = []jsonColumn{
+		{"JobId", jsonField("job_id")},
+		{"ArrayJobId", jsonField("array_job_id")},
+		{"ArrayTaskId", jsonField("array_task_id")},
+		{"JobName", jsonField("name")},
+		{"UserId", jsonNameWithId("user_name", "user_id")},
+		{"GroupId", jsonNameWithId("group_name", "group_id")},
+		{"MCS_label", jsonField("mcs_label")},
+		{"Priority", jsonField("priority")},
+		{"Nice", jsonField("nice")},
+		{"Account", jsonField("account")},
+		{"QOS", jsonField("qos")},
+		{"WCKey", jsonField("wckey")},
+		{"JobState", jsonState("job_state")},
+		{"Reason", jsonField("state_reason")},
+		{"Dependency", jsonField("dependency")},
+		{"Requeue", jsonField("requeue")},
+		{"Restarts", jsonField("restart_cnt")},
+		{"BatchFlag", jsonField("batch_flag")},
+		{"Reboot", jsonField("reboot")},
+		{"ExitCode", jsonExitCode("exit_code")},
+		{"DerivedExitCode", jsonExitCode("derived_exit_code")},
+		{"RunTime", jsonRunTime("job_state", "start_time", "end_time")},
+		{"TimeLimit", jsonMinutes("time_limit")},
+		{"TimeMin", jsonMinutes("time_minimum")},
+		{"SubmitTime", jsonTimestamp("submit_time")},
+		{"EligibleTime", jsonTimestamp("eligible_time")},
+		{"AccrueTime", jsonTimestamp("accrue_time")},
+		{"StartTime", jsonTimestamp("start_time")},
+		{"EndTime", jsonTimestamp("end_time")},
+		{"Deadline", jsonTimestamp("deadline")},
+		{"SuspendTime", jsonTimestamp("suspend_time")},
+		{"LastSchedEval", jsonTimestamp("last_sched_evaluation")},
+		{"Partition", jsonField("partition")},
+		{"ReqNodeList", jsonField("required_nodes")},
+		{"ExcNodeList", jsonField("excluded_nodes")},
+		{"NodeList", jsonField("nodes")},
+		{"BatchHost", jsonField("batch_host")},
+		{"NumNodes", jsonField("node_count")},
+		{"NumCPUs", jsonField("cpus")},
+		{"NumTasks", jsonField("tasks")},
+		{"CPUs/Task", jsonField("cpus_per_task")},
+		{"ReqTRES", jsonField("tres_req_str")},
+		{"AllocTRES", jsonField("tres_alloc_str")},
+		{"MinMemoryNode", jsonField("memory_per_node")},
+		{"Features", jsonField("features")},
+		{"Contiguous", jsonField("contiguous")},
+		{"Licenses", jsonField("licenses")},
+		{"Network", jsonField("network")},
+		{"Reservation", jsonField("resv_name")},
+		{"Command", jsonField("command")},
+		{"WorkDir", jsonField("current_working_directory")},
+		{"StdErr", jsonField("standard_error")},
+		{"StdIn", jsonField("standard_input")},
+		{"StdOut", jsonField("standard_output")},
+		{"Comment", jsonField("comment")},
+		{"AdminComment", jsonField("admin_comment")},
+	}
+
+	jsonPartitionColumns = []jsonColumn{
+		{"PartitionName", jsonField("name")},
+		{"Nodes", jsonField("nodes.configured")},
+		{"TotalCPUs", jsonField("cpus.total")},
+		{"TotalNodes", jsonField("nodes.total")},
+		{"State", jsonState("partition.state")},
+	}
+
+	jsonSacctColumns = []jsonColumn{
+		{"JobIDRaw", jsonField("job_id")},
+		{"JobID", jsonArrayJobId("job_id", "array.job_id", "array.task_id")},
+		{"JobName", jsonField("name")},
+		{"Partition", jsonField("partition")},
+		{"State", jsonField("state.current")},
+		{"Reason", jsonField("state.reason")},
+		{"User", jsonField("user")},
+		{"Group", jsonField("group")},
+		{"Account", jsonField("account")},
+		{"QOS", jsonField("qos")},
+		{"Cluster", jsonField("cluster")},
+		{"NodeList", jsonField("nodes")},
+		{"AllocNodes", jsonField("allocation_nodes")},
+		{"NNodes", jsonField("allocation_nodes")},
+		{"ReqCPUS", jsonField("required.CPUs")},
+		{"AllocCPUS", jsonTresCount("tres.allocated", "cpu")},
+		{"NCPUS", jsonTresCount("tres.allocated", "cpu")},
+		{"ReqMem", jsonField("required.memory_per_node")},
+		{"Elapsed", jsonSeconds("time.elapsed")},
+		{"ElapsedRaw", jsonField("time.elapsed")},
+		{"Timelimit", jsonMinutes("time.limit")},
+		{"TimelimitRaw", jsonField("time.limit")},
+		{"Submit", jsonTimestamp("time.submission")},
+		{"Eligible", jsonTimestamp("time.eligible")},
+		{"Start", jsonTimestamp("time.start")},
+		{"End", jsonTimestamp("time.end")},
+		{"Suspended", jsonSeconds("time.suspended")},
+		{"TotalCPU", jsonSeconds("time.total.seconds")},
+		{"UserCPU", jsonSeconds("time.user.seconds")},
+		{"SystemCPU", jsonSeconds("time.system.seconds")},
+		{"ExitCode", jsonExitCode("exit_code")},
+		{"DerivedExitCode", jsonExitCode("derived_exit_code")},
+		{"ReqTRES", jsonField("tres.requested")},
+		{"AllocTRES", jsonField("tres.allocated")},
+		{"Priority", jsonField("priority")},
+		{"Reservation", jsonField("reservation.name")},
+		{"ReservationId", jsonField("reservation.id")},
+		{"WCKey", jsonField("wckey.wckey")},
+		{"McsLabel", jsonField("mcs.label")},
+		{"Constraints", jsonField("constraints")},
+		{"Container", jsonField("container")},
+		{"Flags", jsonField("flags")},
+		{"FailedNode", jsonField("failed_node")},
+		{"Extra", jsonField("extra")},
+		{"Licenses", jsonField("licenses")},
+		{"WorkDir", jsonField("working_directory")},
+		{"Comment", jsonField("comment.job")},
+		{"AdminComment", jsonField("comment.administrator")},
+		{"SystemComment", jsonField("comment.system")},
+		{"SubmitLine", jsonField("submit_line")},
+	}
+
+	// Keys dropped before flattening, as they are large and not useful as table columns
+	jsonIgnoredKeys = []string{"steps", "script"}
+)
+
+// parseJSONEntries extracts the list under `listKey` from a decoded JSON document,
+// and converts each entry into a flat map keyed by the given column mappings.
+// The raw flattened keys are kept as well, so any JSON field can be used as a column.
+func parseJSONEntries(document map[string]any, listKey string, columns []jsonColumn) (entries []map[string]string) {
+	rawList, ok := document[listKey].([]any)
+	if !ok {
+		return entries
+	}
+
+	for _, rawEntry := range rawList {
+		object, ok := rawEntry.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, key := range jsonIgnoredKeys {
+			delete(object, key)
+		}
+
+		flat := make(map[string]string)
+		flattenJSON("", object, flat)
+
+		entry := make(map[string]string, len(flat)+len(columns))
+		for key, value := range flat {
+			entry[key] = value
+		}
+		for _, column := range columns {
+			value := column.Value(flat)
+			// Format memory-related fields, same as the text parsers
+			if strings.HasSuffix(column.Name, "Mem") ||
+				strings.HasSuffix(column.Name, "Memory") {
+				value = formatMemoryValue(value)
+			}
+			entry[column.Name] = value
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// parseJSONOutput decodes raw JSON output and parses the entries under `listKey`.
+func parseJSONOutput(output []byte, listKey string, columns []jsonColumn) ([]map[string]string, error) {
+	var document map[string]any
+	if err := json.Unmarshal(output, &document); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON output: %v", err)
+	}
+	return parseJSONEntries(document, listKey, columns), nil
+}
+
+// formatJSONEntryAsText formats a parsed entry as 'Key=Value' lines, similar to `scontrol show`
+func formatJSONEntryAsText(entry map[string]string, columns []jsonColumn) string {
+	var sb strings.Builder
+	for _, column := range columns {
+		sb.WriteString(fmt.Sprintf("%s=%s\n", column.Name, entry[column.Name]))
+	}
+	return sb.String()
+}
+
+// formatJSONAsText renders an arbitrary decoded JSON value as indented text,
+// used for outputs that are not tabular, such as diagnostics.
+func formatJSONAsText(value any, indent string, sb *strings.Builder) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			switch child := v[key].(type) {
+			case map[string]any, []any:
+				if scalar, ok := slurmNumberToString(child); ok {
+					sb.WriteString(fmt.Sprintf("%s%s: %s\n", indent, key, scalar))
+					continue
+				}
+				sb.WriteString(fmt.Sprintf("%s%s:\n", indent, key))
+				formatJSONAsText(child, indent+"  ", sb)
+			default:
+				sb.WriteString(fmt.Sprintf("%s%s: %s\n", indent, key, jsonScalarToString(child)))
+			}
+		}
+	case []any:
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				sb.WriteString(fmt.Sprintf("%s-\n", indent))
+				formatJSONAsText(item, indent+"  ", sb)
+			default:
+				sb.WriteString(fmt.Sprintf("%s- %s\n", indent, jsonScalarToString(item)))
+			}
+		}
+	default:
+		sb.WriteString(fmt.Sprintf("%s%s\n", indent, jsonScalarToString(v)))
+	}
+}
+
+// flattenJSON flattens nested objects into a single-level map, joining keys with '.'.
+// Slurm number objects ({set, infinite, number}) are collapsed into their value,
+// lists of scalars are joined with ',' and TRES lists are formatted as 'type=count'.
+func flattenJSON(prefix string, value any, out map[string]string) {
+	if scalar, ok := slurmNumberToString(value); ok {
+		out[prefix] = scalar
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenJSON(key, child, out)
+		}
+	case []any:
+		out[prefix] = jsonListToString(v)
+	default:
+		out[prefix] = jsonScalarToString(v)
+	}
+}
+
+// slurmNumberToString collapses the {set, infinite, number} objects used by the
+// data_parser plugins for optional numbers.
+func slurmNumberToString(value any) (string, bool) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	number, hasNumber := object["number"]
+	_, hasSet := object["set"]
+	if !hasNumber || !hasSet || len(object) > 3 {
+		return "", false
+	}
+
+	if infinite, _ := object["infinite"].(bool); infinite {
+		return "UNLIMITED", true
+	}
+	if set, _ := object["set"].(bool); !set {
+		return "", true
+	}
+	return jsonScalarToString(number), true
+}
+
+func jsonListToString(list []any) string {
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		switch v := item.(type) {
+		case map[string]any:
+			if tres, ok := tresToString(v); ok {
+				values = append(values, tres)
+			} else {
+				raw, _ := json.Marshal(v)
+				values = append(values, string(raw))
+			}
+		default:
+			values = append(values, jsonScalarToString(v))
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+// tresToString formats TRES objects ({type, name, count}) the same way sacct does, e.g. 'gres/gpu=2'
+func tresToString(object map[string]any) (string, bool) {
+	tresType, hasType := object["type"].(string)
+	count, hasCount := object["count"]
+	if !hasType || !hasCount {
+		return "", false
+	}
+
+	name := tresType
+	if tresName, _ := object["name"].(string); tresName != "" {
+		name = fmt.Sprintf("%s/%s", tresType, tresName)
+	}
+	value := jsonScalarToString(count)
+	if tresType == "mem" {
+		value += "M"
+	}
+	return fmt.Sprintf("%s=%s", name, value), true
+}
+
+func jsonScalarToString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		if v == float64(int64(v)) {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Value helpers for column mappings
+
+func jsonField(key string) func(map[string]string) string {
+	return func(flat map[string]string) string {
+		return flat[key]
+	}
+}
+
+// jsonState joins state flags with '+', e.g. 'IDLE+DRAIN', as scontrol does.
+func jsonState(key string) func(map[string]string) string {
+	return func(flat map[string]string) string {
+		return strings.ReplaceAll(flat[key], ",", "+")
+	}
+}
+
+// jsonNameWithId formats user/group fields like scontrol, e.g. 'johndoe(1337)'
+func jsonNameWithId(nameKey, idKey string) func(map[string]string) string {
+	return func(flat map[string]string) string {
+		if flat[idKey] == "" {
+			return flat[nameKey]
+		}
+		return fmt.Sprintf("%s(%s)", flat[nameKey], flat[idKey])
+	}
+}
+
+// jsonExitCode formats exit codes as 'return_code:signal', supporting both
+// the plain integer and the nested object form used by newer API versions.
+func jsonExitCode(key string) func(map[string]string) string {
+	return func(flat map[string]string) string {
+		if value, ok := flat[key]; ok {
+			return value + ":0"
+		}
+		returnCode, ok := flat[key+".return_code"]
+		if !ok {
+			return ""
+		}
+		signal := flat[key+".signal.id"]
+		if signal == "" {
+			signal = "0"
+		}
+		return fmt.Sprintf("%s:%s", returnCode, signal)
+	}
+}
+
+func jsonArrayJobId(jobIdKey, arrayJobIdKey, arrayTaskIdKey string) func(map[string]string) string {
+	return func(flat map[string]string) string {
+		arrayJobId, taskId := flat[arrayJobIdKey], flat[arrayTaskIdKey]
+		if arrayJobId == "" || arrayJobId == "0" || taskId == "" {
+			return flat[jobIdKey]
+		}
+		return fmt.Sprintf("%s_%s", arrayJobId, taskId)
+	}
+}
+
+// jsonTresCount extracts the count of a single TRES type from a TRES string, e.g. 'cpu=4,mem=8G'
+func jsonTresCount(key string, tresType string) func(map[string]string) string {
+	return func(flat map[string]string) string {
+		for _, part := range strings.Split(flat[key], ",") {
+			if value, found := strings.CutPrefix(part, tresType+"="); found {
+				return value
+			}
+		}
+		return ""
+	}
+}
+
+// jsonCPULoad converts the CPU load, reported by Slurm in hundredths, to the scontrol format
+func jsonCPULoad(key string) func(map[string]string) string {
+	return func(flat map[string]string) string {
+		load, err := strconv.ParseFloat(flat[key], 64)
+		if err != nil {
+			return flat[key]
+		}
+		return fmt.Sprintf("%.2f", load/100)
+	}
+}
+
+func jsonTimestamp(key string) func(map[string]string) string {
+	return func(flat map[string]string) string {
+		return formatUnixTimestamp(flat[key])
+	}
+}
+
+func jsonMinutes(key string) func(map[string]string) string {
+	return func(flat map[string]string) string {
+		minutes, err := strconv.ParseInt(flat[key], 10, 64)
+		if err != nil {
+			return flat[key]
+		}
+		return formatSlurmDuration(time.Duration(minutes) * time.Minute)
+	}
+}
+
+func jsonSeconds(key string) func(map[string]string) string {
+	return func(flat map[string]string) string {
+		seconds, err := strconv.ParseInt(flat[key], 10, 64)
+		if err != nil {
+			return flat[key]
+		}
+		return formatSlurmDuration(time.Duration(seconds) * time.Second)
+	}
+}
+
+// jsonRunTime computes the run time of a job, which is not part of the JSON output
+func jsonRunTime(stateKey, startKey, endKey string) func(map[string]string) string {
+	return func(flat map[string]string) string {
+		start, err := strconv.ParseInt(flat[startKey], 10, 64)
+		if err != nil || start == 0 || strings.Contains(flat[stateKey], "PENDING") {
+			return formatSlurmDuration(0)
+		}
+
+		end := time.Now().Unix()
+		if !strings.Contains(flat[stateKey], "RUNNING") &&
+			!strings.Contains(flat[stateKey], "COMPLETING") {
+			if parsedEnd, err := strconv.ParseInt(flat[endKey], 10, 64); err == nil && parsedEnd >= start {
+				end = parsedEnd
+			}
+		}
+		return formatSlurmDuration(time.Duration(end-start) * time.Second)
+	}
+}
+
+// formatUnixTimestamp converts unix timestamps to the format used by scontrol, e.g. '2025-04-06T09:45:37'
+func formatUnixTimestamp(raw string) string {
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return raw
+	}
+	if seconds == 0 {
+		return "Unknown"
+	}
+	return time.Unix(seconds, 0).Local().Format("2006-01-02T15:04:05")
+}
+
+// formatSlurmDuration formats a duration in the Slurm style, [days-]hours:minutes:seconds
+func formatSlurmDuration(d time.Duration) string {
+	totalSeconds := int64(d.Seconds())
+	days := totalSeconds / 86400
+	hours := (totalSeconds % 86400) / 3600
+	minutes := (totalSeconds % 3600) / 60
+	seconds := totalSeconds % 60
+	if days > 0 {
+		return fmt.Sprintf("%d-%02d:%02d:%02d", days, hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+}
@@ -6,25 +6,25 @@ import (
 
 type JobsProvider struct {
 	BaseProvider[*TableData]
+	fetcher Fetcher
+	columns *[]config.ColumnConfig // Columns of the last fetch
 }
 
-func NewJobsProvider() *JobsProvider {
+func NewJobsProvider(fetcher Fetcher) *JobsProvider {
 	p := JobsProvider{
 		BaseProvider: BaseProvider[*TableData]{},
+		fetcher:      fetcher,
 	}
 	p.Fetch()
 	return &p
 }
 
 func (p *JobsProvider) Fetch() error {
-	// Compute column widths on first fetch only
-	computeColumnWidths := false
-	if p.lastUpdated.IsZero() {
-		computeColumnWidths = true
-	}
-	rawData, err := getScontrolDataWithTimeout(
-		"show job --detail --all --oneliner",
-		config.JobViewColumns,
+	// Compute column widths on first fetch, and when columns have been changed at runtime
+	columns := config.JobViewColumns
+	computeColumnWidths := p.columns != columns
+	rawData, err := p.fetcher.Jobs(
+		columns,
 		config.RequestTimeout,
 		computeColumnWidths,
 	)
@@ -33,6 +33,7 @@ func (p *JobsProvider) Fetch() error {
 		return err
 	}
 
+	p.columns = columns
 	p.updateData(rawData)
 	return nil
 }
@@ -6,25 +6,25 @@ import (
 
 type NodesProvider struct {
 	BaseProvider[*TableData]
+	fetcher Fetcher
+	columns *[]config.ColumnConfig // Columns of the last fetch
 }
 
-func NewNodesProvider() *NodesProvider {
+func NewNodesProvider(fetcher Fetcher) *NodesProvider {
 	p := NodesProvider{
 		BaseProvider: BaseProvider[*TableData]{},
+		fetcher:      fetcher,
 	}
 	p.Fetch()
 	return &p
 }
 
 func (p *NodesProvider) Fetch() error {
-	// Compute column widths on first fetch only
-	computeColumnWidths := false
-	if p.lastUpdated.IsZero() {
-		computeColumnWidths = true
-	}
-	rawData, err := getScontrolDataWithTimeout(
-		"show node --detail --all --oneliner",
-		config.NodeViewColumns,
+	// Compute column widths on first fetch, and when columns have been changed at runtime
+	columns := config.NodeViewColumns
+	computeColumnWidths := p.columns != columns
+	rawData, err := p.fetcher.Nodes(
+		columns,
 		config.RequestTimeout,
 		computeColumnWidths,
 	)
@@ -33,6 +33,7 @@ func (p *NodesProvider) Fetch() error {
 		return err
 	}
 
+	p.columns = columns
 	p.updateData(rawData)
 	return nil
 }
@@ -6,22 +6,22 @@ import (
 
 type PartitionsProvider struct {
 	BaseProvider[*TableData]
+	fetcher Fetcher
 }
 
-func NewPartitionsProvider() *PartitionsProvider {
+func NewPartitionsProvider(fetcher Fetcher) *PartitionsProvider {
 	p := PartitionsProvider{
 		BaseProvider: BaseProvider[*TableData]{},
+		fetcher:      fetcher,
 	}
 	p.Fetch()
 	return &p
 }
 
 func (p *PartitionsProvider) Fetch() error {
-	rawData, err := getScontrolDataWithTimeout(
-		"show partitions --detail --all --oneliner",
+	rawData, err := p.fetcher.Partitions(
 		&[]config.ColumnConfig{{RawName: "PartitionName", DisplayName: "PartitionName"}},
 		config.RequestTimeout,
-		false, // Don't compute column widths, doesn't matter here.
 	)
 
 	if err != nil {
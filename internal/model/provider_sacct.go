@@ -8,25 +8,26 @@ import (
 
 type SacctProvider struct {
 	BaseProvider[*TableData]
+	fetcher Fetcher
+	columns *[]config.ColumnConfig // Columns of the last fetch
 }
 
-func NewSacctProvider() *SacctProvider {
+func NewSacctProvider(fetcher Fetcher) *SacctProvider {
 	p := SacctProvider{
 		BaseProvider: BaseProvider[*TableData]{},
+		fetcher:      fetcher,
 	}
 	p.Fetch()
 	return &p
 }
 
 func (p *SacctProvider) Fetch() error {
-	// Compute column widths on first fetch only
-	computeColumnWidths := false
-	if p.lastUpdated.IsZero() {
-		computeColumnWidths = true
-	}
-	rawData, err := getSacctDataSinceWithTimeout(
-		config.LoadSacctDataFrom,
-		config.SacctViewColumns,
+	// Compute column widths on first fetch, and when columns have been changed at runtime
+	columns := config.SacctViewColumns
+	computeColumnWidths := p.columns != columns
+	rawData, err := p.fetcher.Sacct(
+		CurrentSacctQuery(),
+		columns,
 		time.Duration(
 			config.SacctTimeoutMultiplier*config.RequestTimeout.Milliseconds(),
 		)*time.Millisecond,
@@ -40,6 +41,7 @@ func (p *SacctProvider) Fetch() error {
 		p.updateError(err)
 		return err
 	}
+	p.columns = columns
 	return nil
 }
 
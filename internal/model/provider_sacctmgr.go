@@ -1,7 +1,6 @@
 package model
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/antvirf/stui/internal/config"
@@ -9,11 +8,13 @@ import (
 
 type SacctMgrProvider struct {
 	BaseProvider[*TableData]
+	fetcher Fetcher
 }
 
-func NewSacctMgrProvider() *SacctMgrProvider {
+func NewSacctMgrProvider(fetcher Fetcher) *SacctMgrProvider {
 	p := SacctMgrProvider{
 		BaseProvider: BaseProvider[*TableData]{},
+		fetcher:      fetcher,
 	}
 	p.Fetch()
 	return &p
@@ -26,11 +27,10 @@ func (p *SacctMgrProvider) Fetch() error {
 		columns = append(columns, config.ColumnConfig{RawName: key, DisplayName: key})
 	}
 
-	rawData, err := getSacctMgrDataWithTimeout(
-		fmt.Sprintf("show %s --parsable2", config.SacctMgrCurrentEntity),
-		config.RequestTimeout,
+	rawData, err := p.fetcher.SacctMgr(
+		config.SacctMgrCurrentEntity,
 		&columns,
-		false, // For sacctmgr we don't compute column widths for now.
+		config.RequestTimeout,
 	)
 
 	// Empty table data is returned in case of error, so this is always valid to do
@@ -6,18 +6,20 @@ import (
 
 type SdiagProvider struct {
 	BaseProvider[*TextData]
+	fetcher Fetcher
 }
 
-func NewSdiagProvider() *SdiagProvider {
+func NewSdiagProvider(fetcher Fetcher) *SdiagProvider {
 	p := SdiagProvider{
 		BaseProvider: BaseProvider[*TextData]{},
+		fetcher:      fetcher,
 	}
 	p.Fetch()
 	return &p
 }
 
 func (p *SdiagProvider) Fetch() error {
-	rawData, err := getSdiagWithTimeout(config.RequestTimeout)
+	rawData, err := p.fetcher.Sdiag(config.RequestTimeout)
 
 	if err != nil {
 		p.updateError(err)
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/antvirf/stui/internal/config"
+)
+
+// SstatProvider fetches live usage of running jobs. Unlike the other providers, it has
+// no data until the jobs to show are set with SetJobIds.
+type SstatProvider struct {
+	BaseProvider[*TableData]
+	fetcher Fetcher
+	jobIDs  []string
+	jobsMu  sync.Mutex
+}
+
+func NewSstatProvider(fetcher Fetcher) *SstatProvider {
+	p := SstatProvider{
+		BaseProvider: BaseProvider[*TableData]{data: EmptyTableData()},
+		fetcher:      fetcher,
+	}
+	return &p
+}
+
+// SetJobIds sets the jobs to fetch usage for on the next Fetch
+func (p *SstatProvider) SetJobIds(jobIDs []string) {
+	p.jobsMu.Lock()
+	defer p.jobsMu.Unlock()
+	p.jobIDs = jobIDs
+}
+
+func (p *SstatProvider) Fetch() error {
+	p.jobsMu.Lock()
+	jobIDs := p.jobIDs
+	p.jobsMu.Unlock()
+
+	if len(jobIDs) == 0 {
+		err := errors.New("no running jobs selected")
+		p.updateData(EmptyTableData())
+		p.updateError(err)
+		return err
+	}
+
+	// Column widths are always computed, as the rows are for different jobs every time
+	columns := make([]config.ColumnConfig, len(*config.SstatViewColumns))
+	copy(columns, *config.SstatViewColumns)
+	rawData, err := p.fetcher.Sstat(jobIDs, &columns, config.RequestTimeout)
+
+	// Empty table data is returned in case of error, so this is always valid to do
+	p.updateData(rawData)
+	if err != nil {
+		p.updateError(err)
+		return err
+	}
+	return nil
+}
+
+// SstatProvider data does not have any categorical filters, so this just returns the current data.
+func (p *SstatProvider) FilteredData() *TableData {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.data.DeepCopy()
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSstatProvider(t *testing.T) {
+	useFakeSlurmBinaries(t, map[string]string{
+		// Fail unless called with the expected arguments
+		"sstat": `[ "$*" = "--parsable2 --allsteps --jobs 6833,6834 --format JobID,AveCPU,AveRSS,MaxRSS,MaxDiskRead,MaxDiskWrite" ] || exit 1
+cat testdata/sstat.txt`,
+	})
+
+	provider := NewSstatProvider(&CliFetcher{})
+	assert.Equal(t, 0, provider.Length())
+	assert.Error(t, provider.Fetch())
+
+	provider.SetJobIds([]string{"6833", "6834"})
+	require.NoError(t, provider.Fetch())
+
+	data := provider.FilteredData()
+	require.Len(t, data.Rows, 4)
+	assert.Equal(t, []string{"6833.0", "01:02:45", "14872012K", "15923088K", "12.50G", "3.20G"}, data.Rows[2])
+	assert.Equal(t, "JobID", (*data.Headers)[0].DisplayName)
+	assert.Equal(t, 11, (*data.Headers)[0].Width)
+}
+
+func TestSstatNotSupportedBySlurmRestd(t *testing.T) {
+	provider := NewSstatProvider(&SlurmRestdFetcher{})
+	provider.SetJobIds([]string{"6833"})
+	assert.ErrorIs(t, provider.Fetch(), errSstatNotSupportedBySlurmRestd)
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"strconv"
+
+	"github.com/antvirf/stui/internal/config"
+)
+
+// TasksProvider lists the tasks in Tasks, newest first
+type TasksProvider struct {
+	BaseProvider[*TableData]
+	tasks *TaskList
+}
+
+func NewTasksProvider(tasks *TaskList) *TasksProvider {
+	p := TasksProvider{
+		BaseProvider: BaseProvider[*TableData]{},
+		tasks:        tasks,
+	}
+	p.Fetch()
+	return &p
+}
+
+func (p *TasksProvider) Fetch() error {
+	columns := []config.ColumnConfig{
+		{RawName: "ID", DisplayName: "ID"},
+		{RawName: "Status", DisplayName: "Status"},
+		{RawName: "ExitCode", DisplayName: "ExitCode"},
+		{RawName: "Page", DisplayName: "Page"},
+		{RawName: "StartTime", DisplayName: "StartTime"},
+		{RawName: "EndTime", DisplayName: "EndTime"},
+		{RawName: "Duration", DisplayName: "Duration"},
+		{RawName: "Command", DisplayName: "Command", FullWidthColumn: true},
+	}
+	for i := range columns {
+		columns[i].Width = len(columns[i].DisplayName)
+	}
+
+	tasks := p.tasks.All()
+	var entries []map[string]string
+	for i := len(tasks) - 1; i >= 0; i-- {
+		task := tasks[i]
+		entry := map[string]string{
+			"ID":        strconv.Itoa(task.ID),
+			"Status":    task.Status(),
+			"Page":      task.Page,
+			"StartTime": task.StartTime.Format("2006-01-02T15:04:05"),
+			"Duration":  formatSlurmDuration(task.Duration()),
+			"Command":   task.Command,
+		}
+		// Left empty while running, and for commands that were stopped or timed out
+		if code := task.ExitCode(); code >= 0 {
+			entry["ExitCode"] = strconv.Itoa(code)
+		}
+		if end := task.EndTime(); !end.IsZero() {
+			entry["EndTime"] = end.Format("2006-01-02T15:04:05")
+		}
+		entries = append(entries, entry)
+	}
+
+	data := entriesToTableData(entries, &columns, true)
+	p.updateData(data)
+	return nil
+}
+
+// TasksProvider data does not have a categorical filter, so this just returns the current data.
+func (p *TasksProvider) FilteredData() *TableData {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	data := *p.data.DeepCopy()
+	return &data
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/recorder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRecording stores the given output as a recording of the given command
+func writeRecording(t *testing.T, dir string, index int, command, output string) {
+	raw, err := json.Marshal(recorder.Recording{
+		Timestamp: time.Now(),
+		Command:   command,
+		Output:    output,
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%04d.json", index)), raw, 0o644))
+}
+
+func TestCliFetcherReplaysRecordings(t *testing.T) {
+	dir := t.TempDir()
+	partitions := readTestData(t, "partitions.txt")
+	writeRecording(t, dir, 1, "/opt/slurm/bin/scontrol show partitions --detail --all --oneliner", partitions)
+	writeRecording(t, dir, 2, "/opt/slurm/bin/scontrol show partitions --detail --all --oneliner", "")
+	writeRecording(t, dir, 3, "sacctmgr show RunAwayJobs --parsable2", readTestData(t, "runaway_jobs.txt"))
+
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+
+	// Each refresh steps to the next recording, staying at the last one
+	provider := NewPartitionsProvider(&CliFetcher{})
+	require.NoError(t, provider.LastError())
+	assert.Equal(t, 7, provider.Length())
+	require.NoError(t, provider.Fetch())
+	assert.Equal(t, 0, provider.Length())
+	require.NoError(t, provider.Fetch())
+	assert.Equal(t, 0, provider.Length())
+
+	previousEntity := config.SacctMgrCurrentEntity
+	defer func() { config.SacctMgrCurrentEntity = previousEntity }()
+	config.SacctMgrCurrentEntity = SACCT_RUNAWAYJOBS_ENTITY
+	sacctMgrProvider := NewSacctMgrProvider(&CliFetcher{})
+	require.NoError(t, sacctMgrProvider.LastError())
+	assert.NotEmpty(t, sacctMgrProvider.FilteredData().Rows)
+
+	_, err := (&CliFetcher{}).Sdiag(time.Second)
+	assert.ErrorContains(t, err, "no recording found for command 'sdiag'")
+}
+
+func TestRecordingCanBeReplayed(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, recorder.EnableRecording(dir))
+	out, err := recorder.Capture("/usr/bin/scontrol ping", func() ([]byte, error) {
+		return []byte("Slurmctld(primary) at linux1 is UP"), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Slurmctld(primary) at linux1 is UP", string(out))
+	_, err = recorder.Capture("GET /slurm/v0.0.41/jobs?start_time=1", func() ([]byte, error) {
+		return nil, fmt.Errorf("slurmrestd returned 401 Unauthorized")
+	})
+	require.Error(t, err)
+	recorder.Disable()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+
+	out, err = recorder.Capture("scontrol ping", func() ([]byte, error) {
+		t.Fatal("commands must not be executed while replaying")
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Slurmctld(primary) at linux1 is UP", string(out))
+
+	_, err = recorder.Capture("GET /slurm/v0.0.41/jobs?start_time=2", nil)
+	assert.EqualError(t, err, "slurmrestd returned 401 Unauthorized")
+}
+
+// Failed commands are replayed as the same kind of error, so they are handled as they were live
+func TestReplayedCommandErrorsKeepTheirKind(t *testing.T) {
+	useFakeSlurmBinaries(t, map[string]string{
+		"sacct": `echo "sacct: error: Problem talking to the database" >&2; exit 2`,
+		"sdiag": `sleep 5`,
+	})
+	sacct := slurmCommand{Binary: "sacct", Timeout: time.Second}
+	sdiag := slurmCommand{Binary: "sdiag", Timeout: 100 * time.Millisecond}
+
+	dir := t.TempDir()
+	require.NoError(t, recorder.EnableRecording(dir))
+	_, sacctErr := runSlurmCommand(sacct)
+	_, sdiagErr := runSlurmCommand(sdiag)
+	recorder.Disable()
+
+	require.NoError(t, recorder.EnableReplay(dir))
+	defer recorder.Disable()
+
+	_, err := runSlurmCommand(sacct)
+	assert.ErrorIs(t, err, ErrCommandFailed)
+	assert.EqualError(t, err, sacctErr.Error())
+	var commandErr *CommandError
+	require.ErrorAs(t, err, &commandErr)
+	assert.Equal(t, 2, commandErr.ExitCode)
+
+	// Replayed with a longer timeout, the recorded message is kept
+	sdiag.Timeout = time.Second
+	_, err = runSlurmCommand(sdiag)
+	assert.ErrorIs(t, err, ErrCommandTimeout)
+	assert.EqualError(t, err, sdiagErr.Error())
+}
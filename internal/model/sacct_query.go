@@ -0,0 +1,167 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+)
+
+// Separator between start and end time in a time range, e.g. '2025-01-01T09:00..2025-01-01T12:00'
+const SACCT_TIME_RANGE_SEPARATOR = ".."
+
+// Keys accepted in sacct filters, e.g. 'user=alice,bob state=FAILED'
+var SACCT_FILTER_KEYS = []string{"user", "account", "state"}
+
+// SacctQuery selects which jobs are loaded from sacct. The filters are applied by
+// sacct itself, which keeps large time windows fast.
+type SacctQuery struct {
+	Since     time.Duration // Load data starting from this long ago, if StartTime is not set
+	StartTime string        // Absolute start time, passed to sacct as-is, e.g. '2025-01-01T09:00'
+	EndTime   string        // Absolute end time, passed to sacct as-is
+	Users     string        // Comma-separated list of users
+	Accounts  string        // Comma-separated list of accounts
+	States    string        // Comma-separated list of job states
+	Cluster   string        // Cluster to query, if not the local cluster
+}
+
+// CurrentSacctQuery returns the query for the currently configured time range and filters
+func CurrentSacctQuery() SacctQuery {
+	return SacctQuery{
+		Since:     config.LoadSacctDataFrom,
+		StartTime: config.SacctStartTime,
+		EndTime:   config.SacctEndTime,
+		Users:     config.SacctUsers,
+		Accounts:  config.SacctAccounts,
+		States:    config.SacctStates,
+	}
+}
+
+// args returns the time range and filter arguments for sacct
+func (q SacctQuery) args() []string {
+	var args []string
+	if q.Users == "" {
+		args = append(args, "--allusers")
+	} else {
+		args = append(args, "--user="+q.Users)
+	}
+	if q.StartTime != "" {
+		args = append(args, "--starttime="+q.StartTime)
+	} else {
+		args = append(args, sacctStartTimeArg(q.Since))
+	}
+	if q.EndTime != "" {
+		args = append(args, "--endtime="+q.EndTime)
+	}
+	if q.Accounts != "" {
+		args = append(args, "--account="+q.Accounts)
+	}
+	if q.States != "" {
+		args = append(args, "--state="+q.States)
+	}
+	if q.Cluster != "" {
+		args = append(args, "--clusters="+q.Cluster)
+	}
+	return args
+}
+
+// TimeRangeString formats the time range in the same format accepted by ParseSacctTimeRange
+func (q SacctQuery) TimeRangeString() string {
+	if q.StartTime == "" {
+		return formatDays(q.Since)
+	}
+	if q.EndTime == "" {
+		return q.StartTime
+	}
+	return q.StartTime + SACCT_TIME_RANGE_SEPARATOR + q.EndTime
+}
+
+// FiltersString formats the filters in the same format accepted by ParseSacctFilters
+func (q SacctQuery) FiltersString() string {
+	var filters []string
+	for _, filter := range []struct{ key, value string }{
+		{"user", q.Users},
+		{"account", q.Accounts},
+		{"state", q.States},
+	} {
+		if filter.value != "" {
+			filters = append(filters, filter.key+"="+filter.value)
+		}
+	}
+	return strings.Join(filters, " ")
+}
+
+// ParseSacctTimeRange parses a time range, which is either a relative duration such as '2h' or '7d',
+// or absolute start and optional end times in any format sacct accepts, separated by '..',
+// e.g. '2025-01-01T09:00..2025-01-01T12:00'. Absolute times are validated by sacct itself.
+func ParseSacctTimeRange(input string) (since time.Duration, startTime, endTime string, err error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return 0, "", "", fmt.Errorf("time range cannot be empty")
+	}
+
+	if duration, err := parseDays(input); err == nil {
+		if duration <= 0 {
+			return 0, "", "", fmt.Errorf("time range must be positive")
+		}
+		return duration, "", "", nil
+	}
+
+	startTime, endTime, _ = strings.Cut(input, SACCT_TIME_RANGE_SEPARATOR)
+	startTime, endTime = strings.TrimSpace(startTime), strings.TrimSpace(endTime)
+	if startTime == "" || strings.ContainsAny(startTime+endTime, " \t") {
+		return 0, "", "", fmt.Errorf("invalid time range '%s', use e.g. '2h', '7d' or '2025-01-01T09:00..2025-01-01T12:00'", input)
+	}
+	return 0, startTime, endTime, nil
+}
+
+// ParseSacctFilters parses space-separated 'key=value' filters, e.g. 'user=alice,bob state=FAILED'.
+// Keys that are not given are returned empty.
+func ParseSacctFilters(input string) (users, accounts, states string, err error) {
+	values := map[string]string{}
+	for _, filter := range strings.Fields(input) {
+		key, value, found := strings.Cut(filter, "=")
+		if !slices.Contains(SACCT_FILTER_KEYS, key) || !found || value == "" {
+			return "", "", "", fmt.Errorf("invalid filter '%s', use e.g. 'user=alice account=physics state=FAILED'", filter)
+		}
+		values[key] = value
+	}
+	return values["user"], values["account"], values["state"], nil
+}
+
+// parseDays parses a duration like time.ParseDuration, but also accepts days, e.g. '7d' or '1d12h'
+func parseDays(input string) (time.Duration, error) {
+	days, rest, found := strings.Cut(input, "d")
+	if !found {
+		return time.ParseDuration(input)
+	}
+	dayCount, err := strconv.Atoi(days)
+	if err != nil {
+		return 0, err
+	}
+	duration := time.Duration(dayCount) * 24 * time.Hour
+	if rest != "" {
+		remainder, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, err
+		}
+		duration += remainder
+	}
+	return duration, nil
+}
+
+// formatDays formats a duration for display, using days for whole days, e.g. '7d'
+func formatDays(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return d.String()
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSacctTimeRange(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedSince time.Duration
+		expectedStart string
+		expectedEnd   string
+		expectError   bool
+	}{
+		{input: "2h", expectedSince: 2 * time.Hour},
+		{input: " 7d ", expectedSince: 7 * 24 * time.Hour},
+		{input: "1d12h", expectedSince: 36 * time.Hour},
+		{input: "2025-01-01T09:00..2025-01-01T12:00", expectedStart: "2025-01-01T09:00", expectedEnd: "2025-01-01T12:00"},
+		{input: "now-7days", expectedStart: "now-7days"},
+		{input: "2025-01-01..", expectedStart: "2025-01-01"},
+		{input: "", expectError: true},
+		{input: "-2h", expectError: true},
+		{input: "..2025-01-01", expectError: true},
+		{input: "yesterday 09:00", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			since, start, end, err := ParseSacctTimeRange(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSince, since)
+			assert.Equal(t, tt.expectedStart, start)
+			assert.Equal(t, tt.expectedEnd, end)
+		})
+	}
+}
+
+func TestParseSacctFilters(t *testing.T) {
+	users, accounts, states, err := ParseSacctFilters("user=alice,bob  state=FAILED,TIMEOUT")
+	require.NoError(t, err)
+	assert.Equal(t, "alice,bob", users)
+	assert.Equal(t, "", accounts)
+	assert.Equal(t, "FAILED,TIMEOUT", states)
+
+	_, _, _, err = ParseSacctFilters("partition=physics")
+	assert.Error(t, err)
+	_, _, _, err = ParseSacctFilters("user=")
+	assert.Error(t, err)
+	_, _, _, err = ParseSacctFilters("")
+	assert.NoError(t, err)
+}
+
+func TestSacctQueryArgs(t *testing.T) {
+	query := SacctQuery{Since: 2 * time.Hour}
+	assert.Equal(t, []string{"--allusers", "--starttime=now-7200"}, query.args())
+	assert.Equal(t, "2h", query.TimeRangeString())
+	assert.Equal(t, "", query.FiltersString())
+
+	query = SacctQuery{
+		StartTime: "2025-01-01T09:00",
+		EndTime:   "2025-01-01T12:00",
+		Users:     "alice",
+		Accounts:  "physics",
+		States:    "FAILED",
+		Cluster:   "alpha",
+	}
+	assert.Equal(t, []string{
+		"--user=alice",
+		"--starttime=2025-01-01T09:00",
+		"--endtime=2025-01-01T12:00",
+		"--account=physics",
+		"--state=FAILED",
+		"--clusters=alpha",
+	}, query.args())
+	assert.Equal(t, "2025-01-01T09:00..2025-01-01T12:00", query.TimeRangeString())
+	assert.Equal(t, "user=alice account=physics state=FAILED", query.FiltersString())
+}
@@ -10,7 +10,7 @@ import (
 )
 
 func TestNodesProvider(t *testing.T) {
-	provider := NewNodesProvider()
+	provider := NewNodesProvider(&CliFetcher{})
 
 	tests := []struct {
 		name            string
@@ -49,7 +49,7 @@ func TestNodesProvider(t *testing.T) {
 }
 
 func TestJobsProvider(t *testing.T) {
-	provider := NewJobsProvider()
+	provider := NewJobsProvider(&CliFetcher{})
 
 	config.PartitionFilter = config.ALL_CATEGORIES_OPTION
 	data := provider.FilteredData()
@@ -57,14 +57,14 @@ func TestJobsProvider(t *testing.T) {
 	assert.Equal(t, *config.JobViewColumns, *data.Headers)
 
 	firstJobId := data.Rows[0][0]
-	details, err := GetJobDetailsWithTimeout(firstJobId, 1*time.Second)
+	details, err := getJobDetailsWithTimeout(firstJobId, "", 1*time.Second)
 	require.NoError(t, err)
 	assert.Contains(t, details, "JobId="+firstJobId)
 	assert.Contains(t, details, "JobName=")
 }
 
 func TestPartitionsProvider(t *testing.T) {
-	provider := NewPartitionsProvider()
+	provider := NewPartitionsProvider(&CliFetcher{})
 
 	data := provider.FilteredData()
 	assert.Equal(t, 7, len(data.Rows))
@@ -78,14 +78,14 @@ func TestPartitionsProvider(t *testing.T) {
 }
 
 func TestGetNodeDetailsWithTimeout(t *testing.T) {
-	details, err := GetNodeDetailsWithTimeout("linux1", 1*time.Second)
+	details, err := getNodeDetailsWithTimeout("linux1", "", 1*time.Second)
 	require.NoError(t, err)
 	assert.Contains(t, details, "NodeName=linux1")
 	assert.Contains(t, details, "CPUTot=64")
 }
 
 func TestGetSdiagWithTimeout(t *testing.T) {
-	output, err := getSdiagWithTimeout(1 * time.Second)
+	output, err := getSdiagWithTimeout("", 1*time.Second)
 	require.NoError(t, err)
 	assert.Contains(t, output, "Server thread count")
 	assert.Contains(t, output, "Jobs submitted")
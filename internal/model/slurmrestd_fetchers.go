@@ -0,0 +1,147 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/logger"
+)
+
+var (
+	errSacctMgrNotSupportedBySlurmRestd = errors.New("sacctmgr view is not available with the slurmrestd backend")
+	errSstatNotSupportedBySlurmRestd    = errors.New("sstat is not available with the slurmrestd backend")
+)
+
+func getSlurmRestdDataWithTimeout(path string, timeout time.Duration) (map[string]any, error) {
+	startTime := time.Now()
+	FetchCounter.increment()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client := config.NewSlurmRestdClient()
+	out, err := client.GetJSON(ctx, path)
+	execTime := time.Since(startTime).Milliseconds()
+
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			logger.Debugf("slurmrestd: timed out after %dms: GET %s", execTime, path)
+			return nil, fmt.Errorf("timeout after %v", timeout)
+		}
+		logger.Debugf("slurmrestd: failed after %dms: GET %s (%v)", execTime, path, err)
+		return nil, err
+	}
+
+	logger.Debugf("slurmrestd: completed in %dms: GET %s", execTime, path)
+	return out, nil
+}
+
+func getSlurmRestdTableDataWithTimeout(path string, listKey string, jsonColumns []jsonColumn, columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	document, err := getSlurmRestdDataWithTimeout(path, timeout)
+	if err != nil {
+		return EmptyTableData(), err
+	}
+	return entriesToTableData(parseJSONEntries(document, listKey, jsonColumns), columns, computeColumnWidths), nil
+}
+
+func getSlurmRestdNodesWithTimeout(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	client := config.NewSlurmRestdClient()
+	return getSlurmRestdTableDataWithTimeout(client.SlurmPath("nodes"), "nodes", jsonNodeColumns, columns, timeout, computeColumnWidths)
+}
+
+func getSlurmRestdJobsWithTimeout(columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	client := config.NewSlurmRestdClient()
+	return getSlurmRestdTableDataWithTimeout(client.SlurmPath("jobs"), "jobs", jsonJobColumns, columns, timeout, computeColumnWidths)
+}
+
+func getSlurmRestdPartitionsWithTimeout(columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	client := config.NewSlurmRestdClient()
+	return getSlurmRestdTableDataWithTimeout(client.SlurmPath("partitions"), "partitions", jsonPartitionColumns, columns, timeout, false)
+}
+
+func getSlurmRestdSacctDataWithTimeout(sacctQuery SacctQuery, columns *[]config.ColumnConfig, timeout time.Duration, computeColumnWidths bool) (*TableData, error) {
+	client := config.NewSlurmRestdClient()
+	query := url.Values{}
+	if sacctQuery.StartTime != "" {
+		// slurmrestd accepts the same time formats as sacct
+		query.Set("start_time", sacctQuery.StartTime)
+	} else {
+		startTime := time.Now().Add(-max(config.RefreshInterval, sacctQuery.Since, time.Second))
+		query.Set("start_time", fmt.Sprint(startTime.Unix()))
+	}
+	for key, value := range map[string]string{
+		"end_time": sacctQuery.EndTime,
+		"users":    sacctQuery.Users,
+		"account":  sacctQuery.Accounts,
+		"state":    sacctQuery.States,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	return getSlurmRestdTableDataWithTimeout(
+		client.SlurmdbPath("jobs")+"?"+query.Encode(),
+		"jobs", jsonSacctColumns, columns, timeout, computeColumnWidths,
+	)
+}
+
+func getSlurmRestdSdiagWithTimeout(timeout time.Duration) (string, error) {
+	client := config.NewSlurmRestdClient()
+	document, err := getSlurmRestdDataWithTimeout(client.SlurmPath("diag"), timeout)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	formatJSONAsText(document["statistics"], "", &sb)
+	return sb.String(), nil
+}
+
+func getSlurmRestdNodeDetailsWithTimeout(nodeName string, timeout time.Duration) (string, error) {
+	client := config.NewSlurmRestdClient()
+	return getSlurmRestdDetailsWithTimeout(client.SlurmPath("node/"+url.PathEscape(nodeName)), "nodes", jsonNodeColumns, timeout)
+}
+
+func getSlurmRestdJobDetailsWithTimeout(jobID string, timeout time.Duration) (string, error) {
+	client := config.NewSlurmRestdClient()
+	return getSlurmRestdDetailsWithTimeout(client.SlurmPath("job/"+url.PathEscape(jobID)), "jobs", jsonJobColumns, timeout)
+}
+
+func getSlurmRestdDetailsWithTimeout(path string, listKey string, jsonColumns []jsonColumn, timeout time.Duration) (string, error) {
+	document, err := getSlurmRestdDataWithTimeout(path, timeout)
+	if err != nil {
+		return "", err
+	}
+
+	var sections []string
+	for _, entry := range parseJSONEntries(document, listKey, jsonColumns) {
+		sections = append(sections, formatJSONEntryAsText(entry, jsonColumns))
+	}
+	return strings.Join(sections, "\n"), nil
+}
+
+// getSlurmRestdSacctJobDetailsWithTimeout returns details for a single job in the same
+// pipe-separated format as 'sacct --parsable', so the detail view can render both the same way.
+func getSlurmRestdSacctJobDetailsWithTimeout(jobID string, fields []string, timeout time.Duration) (string, error) {
+	client := config.NewSlurmRestdClient()
+	document, err := getSlurmRestdDataWithTimeout(client.SlurmdbPath("job/"+url.PathEscape(jobID)), timeout)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.Join(fields, "|"))
+	for _, entry := range parseJSONEntries(document, "jobs", jsonSacctColumns) {
+		values := make([]string, len(fields))
+		for i, field := range fields {
+			values[i] = safeGetFromMap(entry, field)
+		}
+		sb.WriteString("\n")
+		sb.WriteString(strings.Join(values, "|"))
+	}
+	return sb.String(), nil
+}
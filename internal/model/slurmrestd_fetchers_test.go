@@ -0,0 +1,183 @@
+package model
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeSlurmRestd starts a local HTTP server that serves the recorded responses
+// in testdata/slurmrestd, and points the config at it for the duration of the test.
+func newFakeSlurmRestd(t *testing.T) {
+	routes := map[string]string{
+		"/slurm/v0.0.41/ping":        "ping.json",
+		"/slurm/v0.0.41/nodes":       "nodes.json",
+		"/slurm/v0.0.41/node/linux2": "nodes.json",
+		"/slurm/v0.0.41/jobs":        "jobs.json",
+		"/slurm/v0.0.41/job/6833":    "jobs.json",
+		"/slurm/v0.0.41/partitions":  "partitions.json",
+		"/slurm/v0.0.41/diag":        "diag.json",
+		"/slurmdb/v0.0.41/jobs":      "slurmdb_jobs.json",
+		"/slurmdb/v0.0.41/job/6700":  "slurmdb_jobs.json",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-SLURM-USER-TOKEN") != "test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errors": [{"description": "authentication failed"}]}`))
+			return
+		}
+		file, ok := routes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", "slurmrestd", file))
+		require.NoError(t, err)
+		w.Write(data)
+	}))
+
+	previousURL, previousToken := config.SlurmRestdURL, config.SlurmRestdToken
+	config.SlurmRestdURL = server.URL
+	config.SlurmRestdToken = "test-token"
+
+	t.Cleanup(func() {
+		server.Close()
+		config.SlurmRestdURL, config.SlurmRestdToken = previousURL, previousToken
+	})
+}
+
+func TestSlurmRestdNodesProvider(t *testing.T) {
+	newFakeSlurmRestd(t)
+	config.PartitionFilter = config.ALL_CATEGORIES_OPTION
+
+	provider := NewNodesProvider(&SlurmRestdFetcher{})
+	require.NoError(t, provider.LastError())
+
+	data := provider.FilteredData()
+	require.Len(t, data.Rows, 2)
+	assert.Equal(t, *config.NodeViewColumns, *data.Headers)
+
+	node := provider.Data().Rows[0]
+	assert.Equal(t, "linux1", node[0])
+	assert.Equal(t, "general,physics", node[config.NodeViewColumnsPartitionIndex])
+	assert.Equal(t, "MIXED", node[config.NodeViewColumnsStateIndex])
+	assert.Contains(t, node, "15.23 / 16 / 64")
+	assert.Equal(t, "IDLE+DRAIN", provider.Data().Rows[1][config.NodeViewColumnsStateIndex])
+	assert.Contains(t, provider.Data().Rows[1], "bad dimm, ticket 1234")
+
+	config.PartitionFilter = "physics"
+	defer func() { config.PartitionFilter = config.ALL_CATEGORIES_OPTION }()
+	assert.Len(t, provider.FilteredData().Rows, 1)
+}
+
+func TestSlurmRestdJobsProvider(t *testing.T) {
+	newFakeSlurmRestd(t)
+	config.PartitionFilter = config.ALL_CATEGORIES_OPTION
+
+	provider := NewJobsProvider(&SlurmRestdFetcher{})
+	require.NoError(t, provider.LastError())
+
+	rows := provider.FilteredData().Rows
+	require.Len(t, rows, 2)
+	assert.Equal(t, "6833", rows[0][0])
+	assert.Equal(t, "physics", rows[0][config.JobsViewColumnsPartitionIndex])
+	assert.Equal(t, "RUNNING", rows[0][config.JobsViewColumnsStateIndex])
+	assert.Equal(t, "PENDING", rows[1][config.JobsViewColumnsStateIndex])
+
+	job, err := provider.Data().GetRowAsMapById("6833")
+	require.NoError(t, err)
+	assert.Equal(t, "johndoe(1337)", job["UserId"])
+	assert.Equal(t, "4", job["NumCPUs"])
+}
+
+func TestSlurmRestdPartitionsProvider(t *testing.T) {
+	newFakeSlurmRestd(t)
+
+	data := NewPartitionsProvider(&SlurmRestdFetcher{}).FilteredData()
+	require.Len(t, data.Rows, 3)
+	assert.Equal(t, "general", data.Rows[0][0])
+	assert.Equal(t, "physics", data.Rows[2][0])
+}
+
+func TestSlurmRestdSacctProvider(t *testing.T) {
+	newFakeSlurmRestd(t)
+	config.PartitionFilter = config.ALL_CATEGORIES_OPTION
+
+	provider := NewSacctProvider(&SlurmRestdFetcher{})
+	require.NoError(t, provider.LastError())
+
+	data := provider.FilteredData()
+	require.Len(t, data.Rows, 2)
+	job, err := data.GetRowAsMapById("6700")
+	require.NoError(t, err)
+	assert.Equal(t, "COMPLETED", job["State"])
+	assert.Equal(t, "johndoe", job["User"])
+	assert.Equal(t, "01:02:05", job["Elapsed"])
+	assert.Equal(t, "0:0", job["ExitCode"])
+	assert.Equal(t, "4 / 4", job["ReqCPUS//AllocCPUS"])
+	assert.Equal(t, "cpu=4,mem=8192M,node=1,gres/gpu=1", job["AllocTRES++"])
+	assert.Equal(t, "nightly run", job["Comment++"])
+
+	job, err = data.GetRowAsMapById("6602")
+	require.NoError(t, err)
+	assert.Equal(t, "1-01:01:01", job["Elapsed"])
+	assert.Equal(t, "0:9", job["ExitCode"])
+}
+
+func TestSlurmRestdSdiagProvider(t *testing.T) {
+	newFakeSlurmRestd(t)
+
+	provider := NewSdiagProvider(&SlurmRestdFetcher{})
+	require.NoError(t, provider.LastError())
+
+	output := provider.Data().Data
+	assert.Contains(t, output, "server_thread_count: 3")
+	assert.Contains(t, output, "jobs_submitted: 630")
+	assert.Contains(t, output, "message_type: REQUEST_NODE_INFO")
+	assert.Contains(t, output, "average_time: 150")
+}
+
+func TestSlurmRestdDetails(t *testing.T) {
+	newFakeSlurmRestd(t)
+	fetcher := &SlurmRestdFetcher{}
+
+	details, err := fetcher.NodeDetails("linux2", 1*time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, details, "NodeName=linux1")
+	assert.Contains(t, details, "Reason=bad dimm, ticket 1234")
+
+	details, err = fetcher.JobDetails("6833", 1*time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, details, "JobId=6833")
+	assert.Contains(t, details, "Command=/home/johndoe/dev/stui/testing/sleep.sh --seconds 3600")
+	assert.Contains(t, details, "TimeLimit=1-00:00:00")
+
+	details, err = fetcher.SacctJobDetails("6700", 1*time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, details, "JobIDRaw|Partition|State|")
+	assert.Contains(t, details, "6700|physics|COMPLETED|")
+}
+
+func TestSlurmRestdAuthenticationError(t *testing.T) {
+	newFakeSlurmRestd(t)
+	config.SlurmRestdToken = "wrong-token"
+
+	provider := NewNodesProvider(&SlurmRestdFetcher{})
+	require.Error(t, provider.LastError())
+	assert.Contains(t, provider.LastError().Error(), "authentication failed")
+}
+
+func TestSacctMgrProviderNotSupportedBySlurmRestd(t *testing.T) {
+	newFakeSlurmRestd(t)
+
+	provider := NewSacctMgrProvider(&SlurmRestdFetcher{})
+	assert.ErrorIs(t, provider.LastError(), errSacctMgrNotSupportedBySlurmRestd)
+}
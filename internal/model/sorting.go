@@ -0,0 +1,246 @@
+package model
+
+import (
+	"cmp"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SortKind determines how values of a column are compared when sorting
+type SortKind int
+
+const (
+	SORT_KIND_STRING    SortKind = iota // Plain string comparison
+	SORT_KIND_NUMBER                    // Integers and floats, optionally with a K/M/G/T/P unit suffix, e.g. '512.0G'
+	SORT_KIND_DURATION                  // Slurm durations, e.g. '1-02:00:00', '23:00:00' or '05:30'
+	SORT_KIND_TIMESTAMP                 // Slurm timestamps, e.g. '2025-01-01T09:00:00'
+	SORT_KIND_JOB_ID                    // Job, array and step IDs, e.g. '1000', '1000_5', '1000_[1-5]' or '1000.batch'
+)
+
+// Kinds tried in order when inferring the kind of a column. Plain integers are numbers, not job IDs.
+var sortKindInferenceOrder = []SortKind{
+	SORT_KIND_NUMBER,
+	SORT_KIND_DURATION,
+	SORT_KIND_TIMESTAMP,
+	SORT_KIND_JOB_ID,
+}
+
+// Values that Slurm uses for missing data. These do not prevent a column from being sorted
+// as e.g. numbers, and are sorted after all other values in ascending order.
+var SORT_PLACEHOLDER_VALUES = []string{"", "N/A", "n/a", "None", "NONE", "(null)", "Unknown", "UNLIMITED", "INFINITE"}
+
+var (
+	numberRegex    = regexp.MustCompile(`^-?\d+(\.\d+)?([KMGTP])?$`)
+	durationRegex  = regexp.MustCompile(`^(\d+-)?\d+(:\d+){0,2}(\.\d+)?$`)
+	jobIdRegex     = regexp.MustCompile(`^(\d+)(?:([_+])(\d+|\[[^\]]*\]))?(?:\.(.+))?$`)
+	timestampRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(:\d{2})?$`)
+)
+
+// Separator used for combined columns ('//'), see entriesToTableData
+const combinedColumnSeparator = " / "
+
+// InferSortKind finds the most specific kind that all values of the column can be parsed as.
+// Combined columns are sorted by their first component.
+func InferSortKind(rows [][]string, column int) SortKind {
+	for _, kind := range sortKindInferenceOrder {
+		parsedAny, parsedAll := false, true
+		for _, row := range rows {
+			value := sortValue(row[column])
+			if slices.Contains(SORT_PLACEHOLDER_VALUES, value) {
+				continue
+			}
+			if _, ok := parseSortValue(value, kind); !ok {
+				parsedAll = false
+				break
+			}
+			parsedAny = true
+		}
+		if parsedAny && parsedAll {
+			return kind
+		}
+	}
+	return SORT_KIND_STRING
+}
+
+// SortKey is one column in a multi-column sort
+type SortKey struct {
+	Column     int
+	Descending bool
+}
+
+// SortRows sorts rows in place by the given keys in order of priority, comparing values by the
+// kind inferred for each column. Values that cannot be parsed as the kind are ordered after those
+// that can. The sort is stable, so rows that tie on all keys keep their order between refreshes.
+func SortRows(rows [][]string, keys []SortKey) {
+	kinds := make([]SortKind, len(keys))
+	for i, key := range keys {
+		kinds[i] = InferSortKind(rows, key.Column)
+	}
+
+	// Parse each value only once, as parsing is much slower than comparing
+	keyedRows := make([]keyedRow, len(rows))
+	for i, row := range rows {
+		keyedRows[i] = keyedRow{row: row, keys: make([]sortKey, len(keys))}
+		for j, key := range keys {
+			keyedRows[i].keys[j] = newSortKey(row[key.Column], kinds[j])
+		}
+	}
+
+	sort.SliceStable(keyedRows, func(i, j int) bool {
+		for k, key := range keys {
+			comparison := keyedRows[i].keys[k].compare(keyedRows[j].keys[k])
+			if comparison == 0 {
+				continue
+			}
+			if key.Descending {
+				return comparison > 0
+			}
+			return comparison < 0
+		}
+		return false
+	})
+	for i := range keyedRows {
+		rows[i] = keyedRows[i].row
+	}
+}
+
+type keyedRow struct {
+	row  []string
+	keys []sortKey
+}
+
+// sortKey is a value parsed for comparison
+type sortKey struct {
+	text   string
+	parsed []float64
+	ok     bool // Whether the value could be parsed as the kind of its column
+}
+
+func newSortKey(value string, kind SortKind) sortKey {
+	key := sortKey{text: sortValue(value)}
+	if kind != SORT_KIND_STRING {
+		key.parsed, key.ok = parseSortValue(key.text, kind)
+	}
+	return key
+}
+
+// compare returns -1, 0 or +1 like cmp.Compare
+func (k sortKey) compare(other sortKey) int {
+	switch {
+	case k.ok && other.ok:
+		for i := range k.parsed {
+			if c := cmp.Compare(k.parsed[i], other.parsed[i]); c != 0 {
+				return c
+			}
+		}
+	case k.ok:
+		return -1
+	case other.ok:
+		return 1
+	}
+	return strings.Compare(k.text, other.text) // E.g. '1000.batch' and '1000.extern'
+}
+
+// sortValue returns the part of a cell used for sorting
+func sortValue(value string) string {
+	first, _, _ := strings.Cut(value, combinedColumnSeparator)
+	return strings.TrimSpace(first)
+}
+
+// parseSortValue parses a value into a sequence of numbers that are compared in order
+func parseSortValue(value string, kind SortKind) ([]float64, bool) {
+	switch kind {
+	case SORT_KIND_NUMBER:
+		return parseNumberWithUnit(value)
+	case SORT_KIND_DURATION:
+		return parseSlurmDuration(value)
+	case SORT_KIND_TIMESTAMP:
+		return parseSlurmTimestamp(value)
+	case SORT_KIND_JOB_ID:
+		return parseJobId(value)
+	}
+	return nil, false
+}
+
+func parseNumberWithUnit(value string) ([]float64, bool) {
+	match := numberRegex.FindStringSubmatch(value)
+	if match == nil {
+		return nil, false
+	}
+	number, err := strconv.ParseFloat(strings.TrimSuffix(value, match[2]), 64)
+	if err != nil {
+		return nil, false
+	}
+	if match[2] != "" {
+		number *= math.Pow(1024, float64(strings.Index("KMGTP", match[2])+1))
+	}
+	return []float64{number}, true
+}
+
+// parseSlurmDuration parses durations in the formats Slurm outputs them: 'D-HH:MM:SS',
+// 'D-HH:MM', 'D-HH', 'HH:MM:SS' and 'MM:SS', with optional fractional seconds.
+func parseSlurmDuration(value string) ([]float64, bool) {
+	if !durationRegex.MatchString(value) {
+		return nil, false
+	}
+	days := 0.0
+	if daysPart, rest, found := strings.Cut(value, "-"); found {
+		days, _ = strconv.ParseFloat(daysPart, 64)
+		value = rest
+		// With days, the remaining parts start from hours
+		for strings.Count(value, ":") < 2 {
+			value += ":00"
+		}
+	} else if !strings.Contains(value, ":") {
+		return nil, false // A plain number is not a duration
+	}
+
+	seconds := 0.0
+	for _, part := range strings.Split(value, ":") {
+		number, _ := strconv.ParseFloat(part, 64)
+		seconds = seconds*60 + number
+	}
+	return []float64{days*24*60*60 + seconds}, true
+}
+
+func parseSlurmTimestamp(value string) ([]float64, bool) {
+	if !timestampRegex.MatchString(value) {
+		return nil, false
+	}
+	layout := "2006-01-02T15:04:05"
+	if len(value) == len("2006-01-02T15:04") {
+		layout = "2006-01-02T15:04"
+	}
+	timestamp, err := time.Parse(layout, value)
+	if err != nil {
+		return nil, false
+	}
+	return []float64{float64(timestamp.Unix())}, true
+}
+
+// parseJobId parses a job ID into its base ID and array task or heterogeneous job offset.
+// Pending array ranges such as '1000_[1-5]' are ordered by their first task ID.
+func parseJobId(value string) ([]float64, bool) {
+	match := jobIdRegex.FindStringSubmatch(value)
+	if match == nil {
+		return nil, false
+	}
+	base, _ := strconv.ParseFloat(match[1], 64)
+	task := -1.0 // Jobs without a task ID are ordered first
+	if match[3] != "" {
+		taskId := strings.TrimLeft(match[3], "[")
+		end := strings.IndexFunc(taskId, func(r rune) bool { return r < '0' || r > '9' })
+		if end >= 0 {
+			taskId = taskId[:end]
+		}
+		if parsed, err := strconv.ParseFloat(taskId, 64); err == nil {
+			task = parsed
+		}
+	}
+	return []float64{base, task}, true
+}
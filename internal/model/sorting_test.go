@@ -0,0 +1,108 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// columnOf wraps values into single-column rows
+func columnOf(values ...string) [][]string {
+	rows := make([][]string, len(values))
+	for i, value := range values {
+		rows[i] = []string{value}
+	}
+	return rows
+}
+
+func TestInferSortKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected SortKind
+	}{
+		{"integers", []string{"16", "128", "2"}, SORT_KIND_NUMBER},
+		{"memory", []string{"512.0G", "64.0G", "N/A"}, SORT_KIND_NUMBER},
+		{"durations", []string{"1-02:00:00", "23:00:00", "05:30"}, SORT_KIND_DURATION},
+		{"timestamps", []string{"2025-01-01T09:00:00", "2024-12-31T23:59:59", "Unknown"}, SORT_KIND_TIMESTAMP},
+		{"job ids", []string{"1000_[1-5]", "999", "1000_12", "1000.batch"}, SORT_KIND_JOB_ID},
+		{"combined", []string{"12.00 / 32 / 64", "N/A / 0 / 64"}, SORT_KIND_NUMBER},
+		{"strings", []string{"alice", "16"}, SORT_KIND_STRING},
+		{"only placeholders", []string{"N/A", ""}, SORT_KIND_STRING},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, InferSortKind(columnOf(tt.values...), 0))
+		})
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []string
+		descending bool
+		expected   []string
+	}{
+		{
+			name:     "integers",
+			values:   []string{"128", "16", "2"},
+			expected: []string{"2", "16", "128"},
+		},
+		{
+			name:     "memory with units",
+			values:   []string{"512.0G", "1.5T", "N/A", "900M", "64.0G"},
+			expected: []string{"900M", "64.0G", "512.0G", "1.5T", "N/A"},
+		},
+		{
+			name:       "durations descending",
+			values:     []string{"23:00:00", "1-02:00:00", "05:30", "UNLIMITED", "2-00"},
+			descending: true,
+			expected:   []string{"UNLIMITED", "2-00", "1-02:00:00", "23:00:00", "05:30"},
+		},
+		{
+			name:     "timestamps",
+			values:   []string{"2025-01-01T09:00:00", "Unknown", "2024-12-31T23:59:59"},
+			expected: []string{"2024-12-31T23:59:59", "2025-01-01T09:00:00", "Unknown"},
+		},
+		{
+			name:     "job ids",
+			values:   []string{"1000_[12-15]", "1000_2", "999", "1000", "1000.batch", "1000_10"},
+			expected: []string{"999", "1000", "1000.batch", "1000_2", "1000_10", "1000_[12-15]"},
+		},
+		{
+			name:     "strings",
+			values:   []string{"bob", "alice", "16"},
+			expected: []string{"16", "alice", "bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := columnOf(tt.values...)
+			SortRows(rows, []SortKey{{Column: 0, Descending: tt.descending}})
+			assert.Equal(t, columnOf(tt.expected...), rows)
+		})
+	}
+}
+
+func TestSortRowsMultipleKeys(t *testing.T) {
+	rows := [][]string{
+		{"1", "gpu", "10"},
+		{"2", "batch", "5"},
+		{"3", "gpu", "200"},
+		{"4", "batch", "5"},
+		{"5", "batch", "30"},
+	}
+
+	// Partition ascending, then priority descending, with ties keeping their original order
+	SortRows(rows, []SortKey{{Column: 1}, {Column: 2, Descending: true}})
+	assert.Equal(t, [][]string{
+		{"5", "batch", "30"},
+		{"2", "batch", "5"},
+		{"4", "batch", "5"},
+		{"3", "gpu", "200"},
+		{"1", "gpu", "10"},
+	}, rows)
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+)
+
+// getSstatDataWithTimeout fetches live usage of all steps of the given running jobs
+func getSstatDataWithTimeout(jobIDs []string, columns *[]config.ColumnConfig, timeout time.Duration) (*TableData, error) {
+	out, err := runSlurmCommand(slurmCommand{
+		Binary: "sstat",
+		Args: []string{
+			"--parsable2",
+			"--allsteps",
+			"--jobs", strings.Join(jobIDs, ","),
+			"--format", strings.Join(config.GetColumnFields(columns), ","),
+		},
+		Timeout: timeout,
+	})
+	if err != nil {
+		return EmptyTableData(), err
+	}
+
+	return entriesToTableData(parseSacctOutput(string(out)), columns, true), nil
+}
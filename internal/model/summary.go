@@ -0,0 +1,179 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Summary functions compute aggregate statistics over the rows currently shown in a view,
+// i.e. after partition/state filters and search are applied. They use the fields kept for each
+// row in TableData.SummaryFields, so they don't depend on the columns shown.
+
+// Fields kept for each fetched row for the summaries, see entriesToTableData
+var SUMMARY_FIELDS = []string{"State", "CPUAlloc", "CPUTot", "AllocMem", "RealMemory", "JobState", "Reason"}
+
+// Node states in the order they are shown in the summary
+var SUMMARY_NODE_STATES = []string{"idle", "mixed", "alloc", "drain", "down", "other"}
+
+// Number of pending reasons to show in the jobs summary
+const SUMMARY_TOP_PENDING_REASONS = 3
+
+// SummarizeNodes counts nodes per state, and totals allocated CPUs and memory
+func SummarizeNodes(rows []map[string]string) string {
+	counts := map[string]int{}
+	var cpuAlloc, cpuTotal, memAlloc, memTotal float64
+	for _, row := range rows {
+		counts[nodeStateCategory(row["State"])]++
+		cpuAlloc += fieldNumber(row, "CPUAlloc")
+		cpuTotal += fieldNumber(row, "CPUTot")
+		memAlloc += fieldNumber(row, "AllocMem")
+		memTotal += fieldNumber(row, "RealMemory")
+	}
+
+	var states []string
+	for _, state := range SUMMARY_NODE_STATES {
+		if counts[state] > 0 {
+			states = append(states, fmt.Sprintf("%d %s", counts[state], state))
+		}
+	}
+	parts := []string{"Nodes: " + joinOrNone(states)}
+	if cpuTotal > 0 {
+		parts = append(parts, fmt.Sprintf("CPUs alloc %d/%d (%s)", int(cpuAlloc), int(cpuTotal), percentage(cpuAlloc, cpuTotal)))
+	}
+	if memTotal > 0 {
+		parts = append(parts, fmt.Sprintf("Mem alloc %.1fT/%.1fT (%s)", memAlloc/1024, memTotal/1024, percentage(memAlloc, memTotal)))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// SummarizeJobs counts running and pending jobs, and the most common pending reasons
+func SummarizeJobs(rows []map[string]string) string {
+	var running, pending, other int
+	reasons := map[string]int{}
+	for _, row := range rows {
+		switch state := row["JobState"]; {
+		case strings.HasPrefix(state, "RUNNING"):
+			running++
+		case strings.HasPrefix(state, "PENDING"):
+			pending++
+			if row["Reason"] != "" {
+				reasons[row["Reason"]]++
+			}
+		default:
+			other++
+		}
+	}
+
+	summary := fmt.Sprintf("Jobs: %d running, %d pending", running, pending)
+	if other > 0 {
+		summary += fmt.Sprintf(", %d other", other)
+	}
+	if len(reasons) > 0 {
+		var top []string
+		for _, reason := range topKeys(reasons, SUMMARY_TOP_PENDING_REASONS) {
+			top = append(top, fmt.Sprintf("%s (%d)", reason, reasons[reason]))
+		}
+		summary += " | Top pending reasons: " + strings.Join(top, ", ")
+	}
+	return summary
+}
+
+// SummarizeSacct computes the share of completed, failed and timed out jobs
+func SummarizeSacct(rows []map[string]string) string {
+	counts := map[string]int{}
+	for _, row := range rows {
+		state := row["State"]
+		switch {
+		case strings.HasPrefix(state, "COMPLETED"):
+			counts["completed"]++
+		case strings.HasPrefix(state, "FAILED"), strings.HasPrefix(state, "OUT_OF_MEMORY"), strings.HasPrefix(state, "NODE_FAIL"):
+			counts["failed"]++
+		case strings.HasPrefix(state, "TIMEOUT"):
+			counts["timeout"]++
+		case strings.HasPrefix(state, "CANCELLED"):
+			counts["cancelled"]++
+		default:
+			counts["other"]++
+		}
+	}
+
+	var parts []string
+	for _, state := range []string{"completed", "failed", "timeout", "cancelled", "other"} {
+		if counts[state] > 0 {
+			parts = append(parts, fmt.Sprintf(
+				"%d %s (%s)", counts[state], state, percentage(float64(counts[state]), float64(len(rows))),
+			))
+		}
+	}
+	return "Jobs: " + joinOrNone(parts)
+}
+
+// nodeStateCategory maps a node state such as 'IDLE+DRAIN' or 'MIXED*' to one of SUMMARY_NODE_STATES.
+// Flags indicating problems take priority over the base state.
+func nodeStateCategory(state string) string {
+	switch {
+	case strings.Contains(state, "DOWN"), strings.Contains(state, "NOT_RESPONDING"), strings.Contains(state, "FAIL"):
+		return "down"
+	case strings.Contains(state, "DRAIN"):
+		return "drain"
+	case strings.HasPrefix(state, "MIX"):
+		return "mixed"
+	case strings.HasPrefix(state, "ALLOC"):
+		return "alloc"
+	case strings.HasPrefix(state, "IDLE"):
+		return "idle"
+	}
+	return "other"
+}
+
+// entrySummaryFields returns the values of SUMMARY_FIELDS in a parsed entry
+func entrySummaryFields(entry map[string]string) map[string]string {
+	fields := make(map[string]string)
+	for _, name := range SUMMARY_FIELDS {
+		if value, found := entry[name]; found {
+			fields[name] = value
+		}
+	}
+	return fields
+}
+
+// fieldNumber parses a field as a number. Returns 0 if the value is not available.
+func fieldNumber(fields map[string]string, name string) float64 {
+	// Memory values are formatted as e.g. '12.5G', see formatMemoryValue
+	number, err := strconv.ParseFloat(strings.TrimSuffix(fields[name], "G"), 64)
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+func joinOrNone(parts []string) string {
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func percentage(value, total float64) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.0f%%", value/total*100)
+}
+
+// topKeys returns up to n keys with the highest counts, ties broken alphabetically
+func topKeys(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys[:min(n, len(keys))]
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeNodes(t *testing.T) {
+	rows := []map[string]string{
+		{"State": "IDLE", "CPUAlloc": "0", "CPUTot": "64", "AllocMem": "0.0G", "RealMemory": "512.0G"},
+		{"State": "MIXED", "CPUAlloc": "32", "CPUTot": "64", "AllocMem": "256.0G", "RealMemory": "512.0G"},
+		{"State": "ALLOCATED", "CPUAlloc": "64", "CPUTot": "64", "AllocMem": "512.0G", "RealMemory": "512.0G"},
+		{"State": "IDLE+DRAIN", "CPUAlloc": "0", "CPUTot": "64", "AllocMem": "0.0G", "RealMemory": "512.0G"},
+		{"State": "DOWN+DRAIN+NOT_RESPONDING", "CPUAlloc": "0", "CPUTot": "64", "AllocMem": "N/A", "RealMemory": "512.0G"},
+	}
+
+	assert.Equal(t,
+		"Nodes: 1 idle, 1 mixed, 1 alloc, 1 drain, 1 down | CPUs alloc 96/320 (30%) | Mem alloc 0.8T/2.5T (30%)",
+		SummarizeNodes(rows),
+	)
+	assert.Equal(t, "Nodes: none", SummarizeNodes(nil))
+
+	// Without CPU and memory fields, e.g. from other Slurm versions, their totals are left out
+	assert.Equal(t, "Nodes: 1 idle", SummarizeNodes([]map[string]string{{"State": "IDLE"}}))
+}
+
+func TestSummarizeJobs(t *testing.T) {
+	rows := []map[string]string{
+		{"JobState": "RUNNING", "Reason": "None"},
+		{"JobState": "RUNNING", "Reason": "None"},
+		{"JobState": "PENDING", "Reason": "Priority"},
+		{"JobState": "PENDING", "Reason": "Priority"},
+		{"JobState": "PENDING", "Reason": "Resources"},
+		{"JobState": "PENDING", "Reason": "QOSMaxJobsPerUserLimit"},
+		{"JobState": "PENDING", "Reason": "Dependency"},
+		{"JobState": "COMPLETING", "Reason": "None"},
+	}
+
+	assert.Equal(t,
+		"Jobs: 2 running, 5 pending, 1 other | Top pending reasons: Priority (2), Dependency (1), QOSMaxJobsPerUserLimit (1)",
+		SummarizeJobs(rows),
+	)
+	assert.Equal(t, "Jobs: 0 running, 1 pending", SummarizeJobs([]map[string]string{{"JobState": "PENDING"}}))
+}
+
+func TestSummarizeSacct(t *testing.T) {
+	rows := []map[string]string{
+		{"State": "COMPLETED"},
+		{"State": "COMPLETED"},
+		{"State": "FAILED"},
+		{"State": "TIMEOUT"},
+		{"State": "CANCELLED by 1000"},
+	}
+
+	assert.Equal(t,
+		"Jobs: 2 completed (40%), 1 failed (20%), 1 timeout (20%), 1 cancelled (20%)",
+		SummarizeSacct(rows),
+	)
+	assert.Equal(t, "Jobs: none", SummarizeSacct(nil))
+}
+
+// Summary fields are kept for fetched rows whether or not they are shown, and follow the rows
+// through filters and search
+func TestSummaryFieldsFollowRows(t *testing.T) {
+	entries := []map[string]string{
+		{"JobId": "1", "Partition": "batch", "JobState": "RUNNING", "Reason": "None", "UserId": "alice"},
+		{"JobId": "2", "Partition": "debug", "JobState": "PENDING", "Reason": "Priority", "UserId": "bob"},
+		{"JobId": "3", "Partition": "batch", "JobState": "PENDING", "Reason": "Resources", "UserId": "bob"},
+	}
+	columns := []config.ColumnConfig{
+		{RawName: "JobId", DisplayName: "JobId"},
+		{RawName: "Partition", DisplayName: "Partition"},
+		{RawName: "JobState", DisplayName: "JobState"},
+	}
+	data := entriesToTableData(entries, &columns, false)
+	require.Len(t, data.SummaryFields, 3)
+	assert.Equal(t, map[string]string{"JobState": "PENDING", "Reason": "Priority"}, data.SummaryFields[1])
+
+	filtered := data.ApplyFilters(map[int]string{1: "batch"})
+	assert.Equal(t, "Jobs: 1 running, 1 pending | Top pending reasons: Resources (1)", SummarizeJobs(filtered.SummaryFields))
+
+	searched, err := filtered.ApplySearch("pend")
+	require.NoError(t, err)
+	assert.Equal(t, "Jobs: 0 running, 1 pending | Top pending reasons: Resources (1)", SummarizeJobs(searched.SummaryFields))
+}
@@ -0,0 +1,334 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/antvirf/stui/internal/audit"
+	"github.com/antvirf/stui/internal/config"
+)
+
+// Statuses of tasks, named like job states so that they are colored the same way
+const (
+	TASK_STATUS_RUNNING   = "RUNNING"
+	TASK_STATUS_COMPLETED = "COMPLETED"
+	TASK_STATUS_FAILED    = "FAILED"
+	TASK_STATUS_TIMEOUT   = "TIMEOUT"
+	TASK_STATUS_CANCELLED = "CANCELLED"
+)
+
+const (
+	// Only the end of the output of noisy commands is kept, e.g. 'tail -f'
+	TASK_OUTPUT_MAX_BYTES = 256 * 1024
+	TASK_OUTPUT_TRUNCATED = "[earlier output truncated]\n"
+
+	// Updates of the output are sent at most this often, so that noisy commands don't flood the UI
+	TASK_UPDATE_INTERVAL = 100 * time.Millisecond
+)
+
+// TaskOptions change how the command of a task is run
+type TaskOptions struct {
+	Timeout time.Duration // No timeout if zero
+	Env     map[string]string
+	Workdir string
+	Targets []string // IDs of the selected rows the command is run for, recorded in the audit log
+
+	// Question asked before the command was run, e.g. for plugins with 'confirm', asked again
+	// before the command is run again from the tasks view
+	Confirmation string
+}
+
+// Task is a shell command run from the UI, e.g. from the command modal or a plugin. Its output
+// is captured as it arrives, so that it can be shown while the command is still running.
+type Task struct {
+	ID        int
+	Command   string
+	Page      string // Page the command was run from
+	Options   TaskOptions
+	StartTime time.Time
+
+	mu        sync.Mutex
+	output    bytes.Buffer
+	truncated bool      // Output was longer than TASK_OUTPUT_MAX_BYTES, and only the end is kept
+	hash      hash.Hash // Of the full output, for the audit log
+	status    string
+	exitCode  int
+	endTime   time.Time
+	killed    bool
+	audited   bool   // Start of the command is in the audit log, so its end must be as well
+	cluster   string // For the audit log, as the profile may be switched while the command runs
+	cancel    context.CancelFunc
+	done      chan struct{}
+	onUpdate  func(*Task)
+
+	updatePending atomic.Bool // An update is scheduled, and includes any output until then
+}
+
+var lastTaskID atomic.Int64
+
+// StartTask runs the command with bash in the background. onUpdate is called from another
+// goroutine whenever output arrives, and when the task ends.
+func StartTask(command string, page string, options TaskOptions, onUpdate func(*Task)) *Task {
+	task := &Task{
+		ID:        int(lastTaskID.Add(1)),
+		Command:   command,
+		Page:      page,
+		Options:   options,
+		StartTime: time.Now(),
+		status:    TASK_STATUS_RUNNING,
+		exitCode:  -1,
+		hash:      sha256.New(),
+		cluster:   config.ClusterName,
+		done:      make(chan struct{}),
+		onUpdate:  onUpdate,
+	}
+
+	var ctx context.Context
+	if options.Timeout > 0 {
+		ctx, task.cancel = context.WithTimeout(context.Background(), options.Timeout)
+	} else {
+		ctx, task.cancel = context.WithCancel(context.Background())
+	}
+
+	cmd := exec.CommandContext(ctx, "bash", "-c", command)
+	if len(options.Env) > 0 {
+		cmd.Env = os.Environ()
+		for name, value := range options.Env {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+	cmd.Dir = options.Workdir
+	cmd.Stdout = taskOutput{task}
+	cmd.Stderr = cmd.Stdout
+
+	// Kill the whole process group, so that children like 'ssh' don't keep running
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	// Commands that cannot be audited are not run at all
+	if err := task.writeAuditEntry(audit.EVENT_START); err != nil {
+		task.finish(ctx, fmt.Errorf("command not run, failed to write audit log: %v", err))
+		return task
+	}
+	task.audited = true
+
+	if err := cmd.Start(); err != nil {
+		task.finish(ctx, err)
+		return task
+	}
+	go func() {
+		task.finish(ctx, cmd.Wait())
+	}()
+	return task
+}
+
+// taskOutput appends the output of the command to the task
+type taskOutput struct {
+	task *Task
+}
+
+func (w taskOutput) Write(p []byte) (int, error) {
+	w.task.mu.Lock()
+	w.task.hash.Write(p)
+	w.task.output.Write(p)
+	if excess := w.task.output.Len() - TASK_OUTPUT_MAX_BYTES; excess > 0 {
+		// Drop whole lines where possible, so that the kept output starts at a line
+		w.task.output.Next(excess)
+		if newline := bytes.IndexByte(w.task.output.Bytes(), '\n'); newline >= 0 && newline < 1024 {
+			w.task.output.Next(newline + 1)
+		}
+		w.task.truncated = true
+	}
+	w.task.mu.Unlock()
+	w.task.scheduleUpdate()
+	return len(p), nil
+}
+
+func (t *Task) finish(ctx context.Context, err error) {
+	t.mu.Lock()
+	t.endTime = time.Now()
+	var exitError *exec.ExitError
+	switch {
+	case t.killed:
+		t.status = TASK_STATUS_CANCELLED
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		t.status = TASK_STATUS_TIMEOUT
+		fmt.Fprintf(&t.output, "\nTimed out after %v\n", t.Options.Timeout)
+	case err == nil:
+		t.status = TASK_STATUS_COMPLETED
+		t.exitCode = 0
+	case errors.As(err, &exitError):
+		t.status = TASK_STATUS_FAILED
+		t.exitCode = exitError.ExitCode()
+	default:
+		t.status = TASK_STATUS_FAILED
+		fmt.Fprintf(&t.output, "Error: %v\n", err)
+	}
+	t.mu.Unlock()
+
+	if t.audited {
+		if err := t.writeAuditEntry(audit.EVENT_END); err != nil {
+			t.mu.Lock()
+			fmt.Fprintf(&t.output, "Error: failed to write audit log: %v\n", err)
+			t.mu.Unlock()
+		}
+	}
+	close(t.done)
+
+	t.cancel()
+	t.notify()
+}
+
+// writeAuditEntry records the start or end of the command, with its outcome once it has ended
+func (t *Task) writeAuditEntry(event string) error {
+	entry := audit.Entry{
+		Event:   event,
+		Task:    t.ID,
+		Cluster: t.cluster,
+		Page:    t.Page,
+		Targets: t.Options.Targets,
+		Command: t.Command,
+	}
+	if event == audit.EVENT_END {
+		t.mu.Lock()
+		exitCode := t.exitCode
+		entry.Status = t.status
+		entry.ExitCode = &exitCode
+		entry.OutputSHA256 = hex.EncodeToString(t.hash.Sum(nil))
+		t.mu.Unlock()
+	}
+	return audit.Write(entry)
+}
+
+// scheduleUpdate calls onUpdate after TASK_UPDATE_INTERVAL, unless an update is already scheduled
+func (t *Task) scheduleUpdate() {
+	if t.updatePending.Swap(true) {
+		return
+	}
+	time.AfterFunc(TASK_UPDATE_INTERVAL, func() {
+		t.updatePending.Store(false)
+		t.notify()
+	})
+}
+
+func (t *Task) notify() {
+	t.mu.Lock()
+	onUpdate := t.onUpdate
+	t.mu.Unlock()
+	if onUpdate != nil {
+		onUpdate(t)
+	}
+}
+
+// SetOnUpdate replaces the function called when output arrives and when the task ends, e.g.
+// when the task is moved from the command modal to the background
+func (t *Task) SetOnUpdate(onUpdate func(*Task)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onUpdate = onUpdate
+}
+
+// Kill stops the command if it is still running
+func (t *Task) Kill() {
+	t.mu.Lock()
+	if t.status == TASK_STATUS_RUNNING {
+		t.killed = true
+	}
+	t.mu.Unlock()
+	t.cancel()
+}
+
+// Done is closed when the task has ended
+func (t *Task) Done() <-chan struct{} {
+	return t.done
+}
+
+// Output returns the combined stdout and stderr of the command so far, or the end of it if it
+// is longer than TASK_OUTPUT_MAX_BYTES
+func (t *Task) Output() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.truncated {
+		return TASK_OUTPUT_TRUNCATED + t.output.String()
+	}
+	return t.output.String()
+}
+
+// Status returns one of the TASK_STATUS_* consts
+func (t *Task) Status() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// ExitCode returns the exit code of the command, or -1 if it is running or didn't exit by itself
+func (t *Task) ExitCode() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.exitCode
+}
+
+// EndTime returns when the task ended, or the zero time if it is still running
+func (t *Task) EndTime() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.endTime
+}
+
+// Duration returns how long the task ran, or has been running so far
+func (t *Task) Duration() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.endTime.IsZero() {
+		return time.Since(t.StartTime)
+	}
+	return t.endTime.Sub(t.StartTime)
+}
+
+// TaskList holds tasks shown in the tasks view
+type TaskList struct {
+	mu    sync.Mutex
+	tasks []*Task
+}
+
+// Tasks are the tasks of this session shown in the tasks view
+var Tasks = &TaskList{}
+
+func (l *TaskList) Add(task *Task) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tasks = append(l.tasks, task)
+}
+
+// All returns all tasks, oldest first
+func (l *TaskList) All() []*Task {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*Task{}, l.tasks...)
+}
+
+// Get returns the task with the given ID, or nil if there is none
+func (l *TaskList) Get(id int) *Task {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, task := range l.tasks {
+		if task.ID == id {
+			return task
+		}
+	}
+	return nil
+}
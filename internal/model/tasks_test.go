@@ -0,0 +1,149 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForTask waits for the task to end, failing the test if it takes too long
+func waitForTask(t *testing.T, task *Task) {
+	select {
+	case <-task.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("task '%s' did not end", task.Command)
+	}
+}
+
+func TestStartTask(t *testing.T) {
+	task := StartTask("echo out; echo err >&2; exit 3", "nodes", TaskOptions{}, nil)
+	waitForTask(t, task)
+
+	assert.Equal(t, TASK_STATUS_FAILED, task.Status())
+	assert.Equal(t, 3, task.ExitCode())
+	assert.Equal(t, "out\nerr\n", task.Output())
+	assert.Equal(t, "nodes", task.Page)
+
+	task = StartTask(`echo "$STUI_TEST_VALUE"; pwd`, "jobs", TaskOptions{
+		Env:     map[string]string{"STUI_TEST_VALUE": "with spaces"},
+		Workdir: os.TempDir(),
+	}, nil)
+	waitForTask(t, task)
+
+	assert.Equal(t, TASK_STATUS_COMPLETED, task.Status())
+	assert.Equal(t, 0, task.ExitCode())
+	assert.Equal(t, "with spaces\n"+filepath.Clean(os.TempDir())+"\n", task.Output())
+}
+
+func TestStartTaskStreamsOutput(t *testing.T) {
+	updates := make(chan string, 10)
+	task := StartTask("echo first; sleep 10; echo second", "nodes", TaskOptions{}, func(task *Task) {
+		updates <- task.Output()
+	})
+	defer task.Kill()
+
+	// The first line arrives while the command is still waiting for input
+	select {
+	case output := <-updates:
+		assert.Equal(t, "first\n", output)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no output streamed from running task")
+	}
+	assert.Equal(t, TASK_STATUS_RUNNING, task.Status())
+}
+
+func TestStartTaskLimitsOutput(t *testing.T) {
+	var updates atomic.Int64
+	// Many small writes of 2 MB in total, with lines numbered from 1
+	task := StartTask("seq 1 300000", "nodes", TaskOptions{}, func(task *Task) {
+		updates.Add(1)
+	})
+	waitForTask(t, task)
+
+	output := task.Output()
+	assert.Equal(t, TASK_STATUS_COMPLETED, task.Status())
+	assert.LessOrEqual(t, len(output), len(TASK_OUTPUT_TRUNCATED)+TASK_OUTPUT_MAX_BYTES)
+	assert.True(t, strings.HasPrefix(output, TASK_OUTPUT_TRUNCATED), "output should note that it was truncated")
+	assert.True(t, strings.HasSuffix(output, "\n299999\n300000\n"))
+
+	// Only whole lines are kept
+	lines := strings.Split(strings.TrimPrefix(output, TASK_OUTPUT_TRUNCATED), "\n")
+	first, err := strconv.Atoi(lines[0])
+	require.NoError(t, err)
+	last, err := strconv.Atoi(lines[len(lines)-2])
+	require.NoError(t, err)
+	assert.Equal(t, len(lines)-2, last-first)
+
+	// Updates are limited by TASK_UPDATE_INTERVAL, instead of one per write
+	assert.Less(t, updates.Load(), int64(time.Since(task.StartTime)/TASK_UPDATE_INTERVAL)+3)
+}
+
+func TestStartTaskTimeout(t *testing.T) {
+	task := StartTask("sleep 10", "nodes", TaskOptions{Timeout: 100 * time.Millisecond}, nil)
+	waitForTask(t, task)
+
+	assert.Equal(t, TASK_STATUS_TIMEOUT, task.Status())
+	assert.Equal(t, -1, task.ExitCode())
+	assert.Contains(t, task.Output(), "Timed out after 100ms")
+}
+
+func TestTaskKill(t *testing.T) {
+	// The child keeps the output open, so it must be killed along with bash
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	task := StartTask("sleep 10 & echo $! > "+pidFile+"; wait", "nodes", TaskOptions{}, nil)
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(pidFile)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	task.Kill()
+	waitForTask(t, task)
+
+	assert.Equal(t, TASK_STATUS_CANCELLED, task.Status())
+	pid, err := os.ReadFile(pidFile)
+	require.NoError(t, err)
+	// The killed child may be a zombie until it is reaped
+	assert.Eventually(t, func() bool {
+		stat, err := os.ReadFile("/proc/" + strings.TrimSpace(string(pid)) + "/stat")
+		return os.IsNotExist(err) || strings.Contains(string(stat), ") Z ")
+	}, 5*time.Second, 10*time.Millisecond, "child process still running after kill")
+}
+
+func TestTasksProvider(t *testing.T) {
+	tasks := &TaskList{}
+	first := StartTask("true", "nodes", TaskOptions{}, nil)
+	second := StartTask("false", "jobs", TaskOptions{}, nil)
+	waitForTask(t, first)
+	waitForTask(t, second)
+	tasks.Add(first)
+	tasks.Add(second)
+
+	assert.Same(t, second, tasks.Get(second.ID))
+	assert.Nil(t, tasks.Get(-1))
+
+	provider := NewTasksProvider(tasks)
+	rows := provider.Data().Rows
+	require.Len(t, rows, 2)
+	// Newest first
+	assert.Equal(t, strconv.Itoa(second.ID), rows[0][0])
+	assert.Equal(t, []string{"FAILED", "1", "jobs"}, rows[0][1:4])
+	assert.Equal(t, []string{"COMPLETED", "0", "nodes"}, rows[1][1:4])
+	assert.Equal(t, second.StartTime.Format("2006-01-02T15:04:05"), rows[0][4])
+	assert.Equal(t, second.EndTime().Format("2006-01-02T15:04:05"), rows[0][5])
+	assert.Equal(t, []string{"00:00:00", "false"}, rows[0][6:])
+
+	// Running tasks have no end time or exit code yet
+	running := StartTask("sleep 10", "nodes", TaskOptions{}, nil)
+	defer running.Kill()
+	tasks.Add(running)
+	provider.Fetch()
+	assert.Equal(t, []string{"RUNNING", "", "nodes"}, provider.Data().Rows[0][1:4])
+	assert.Equal(t, "", provider.Data().Rows[0][5])
+}
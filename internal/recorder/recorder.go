@@ -0,0 +1,236 @@
+// Package recorder stores raw outputs of every command run against Slurm, and can feed them back
+// in place of executing the commands. This makes it possible to reproduce what a user saw
+// on their cluster without access to it, and to run offline demos.
+//
+// Recordings are stored as one JSON file per command execution, named so that
+// sorting by file name gives the order in which they were recorded.
+package recorder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MODE_OFF    = "off"
+	MODE_RECORD = "record"
+	MODE_REPLAY = "replay"
+)
+
+// Recording is a single captured command execution
+type Recording struct {
+	Timestamp time.Time `json:"timestamp"`
+	Command   string    `json:"command"`
+	Output    string    `json:"output"`
+	Error     string    `json:"error,omitempty"`
+	ErrorKind string    `json:"error_kind,omitempty"` // Only for a RecordableError
+	ExitCode  int       `json:"exit_code,omitempty"`  // Only for a RecordableError
+	Stderr    string    `json:"stderr,omitempty"`     // Only for a RecordableError
+}
+
+// RecordableError is implemented by errors whose kind, e.g. a timeout, exit code and stderr are
+// recorded along with the message, so that they can be rebuilt as the same kind of error on replay
+type RecordableError interface {
+	error
+	RecordedDetails() (kind string, exitCode int, stderr string)
+}
+
+// ReplayedError is returned in place of a recorded error on replay. Callers can rebuild their
+// own error from its kind, which is empty if the error was not a RecordableError.
+type ReplayedError struct {
+	Message  string
+	Kind     string
+	ExitCode int
+	Stderr   string
+}
+
+func (e *ReplayedError) Error() string {
+	return e.Message
+}
+
+var (
+	mode      string = MODE_OFF
+	directory string
+	mu        sync.Mutex
+	sequence  int
+
+	// Replay state: recordings grouped by command, and the position of the next one to return
+	replays        map[string][]Recording
+	replayPosition map[string]int
+)
+
+// EnableRecording stores all captured outputs into the given directory, creating it if needed.
+func EnableRecording(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create recording directory: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	mode = MODE_RECORD
+	directory = dir
+	return nil
+}
+
+// EnableReplay loads all recordings from the given directory. Subsequent captures of a command
+// return its recordings in order, one per call, repeating the last one once all have been used.
+func EnableReplay(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no recordings found in '%s'", dir)
+	}
+	sort.Strings(files)
+
+	loaded := map[string][]Recording{}
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read recording '%s': %v", file, err)
+		}
+		var recording Recording
+		if err := json.Unmarshal(raw, &recording); err != nil {
+			return fmt.Errorf("failed to parse recording '%s': %v", file, err)
+		}
+		key := normalizeCommand(recording.Command)
+		loaded[key] = append(loaded[key], recording)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	mode = MODE_REPLAY
+	directory = dir
+	replays = loaded
+	replayPosition = map[string]int{}
+	return nil
+}
+
+// Disable stops recording or replaying, executing commands as normal
+func Disable() {
+	mu.Lock()
+	defer mu.Unlock()
+	mode = MODE_OFF
+	directory = ""
+	replays = nil
+	replayPosition = nil
+}
+
+// Mode returns the current mode, one of MODE_OFF, MODE_RECORD or MODE_REPLAY
+func Mode() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return mode
+}
+
+// Capture runs the given function to produce the output of a command, recording the result if
+// recording is enabled. In replay mode, the function is not called, and a recorded output is returned instead.
+func Capture(command string, run func() ([]byte, error)) ([]byte, error) {
+	if Mode() == MODE_REPLAY {
+		return replay(command)
+	}
+
+	out, err := run()
+	if Mode() == MODE_RECORD {
+		record(command, out, err)
+	}
+	return out, err
+}
+
+func replay(command string) ([]byte, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := normalizeCommand(command)
+	recordings, ok := replays[key]
+	if !ok {
+		return nil, fmt.Errorf("no recording found for command '%s'", key)
+	}
+
+	position := replayPosition[key]
+	recording := recordings[position]
+	if position < len(recordings)-1 {
+		replayPosition[key] = position + 1
+	}
+
+	if recording.Error != "" {
+		return []byte(recording.Output), &ReplayedError{
+			Message:  recording.Error,
+			Kind:     recording.ErrorKind,
+			ExitCode: recording.ExitCode,
+			Stderr:   recording.Stderr,
+		}
+	}
+	return []byte(recording.Output), nil
+}
+
+func record(command string, out []byte, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	recording := Recording{
+		Timestamp: time.Now(),
+		Command:   command,
+		Output:    string(out),
+	}
+	if err != nil {
+		recording.Error = err.Error()
+		var recordable RecordableError
+		if errors.As(err, &recordable) {
+			recording.ErrorKind, recording.ExitCode, recording.Stderr = recordable.RecordedDetails()
+		}
+	}
+
+	sequence++
+	fileName := fmt.Sprintf(
+		"%s-%04d-%s.json",
+		recording.Timestamp.Format("20060102T150405.000"),
+		sequence,
+		binaryName(command),
+	)
+
+	raw, _ := json.MarshalIndent(recording, "", "  ")
+	// Recording is best-effort, and must never break the application itself
+	_ = os.WriteFile(filepath.Join(directory, fileName), raw, 0o644)
+}
+
+// normalizeCommand strips the directory from the binary, so recordings can be
+// replayed regardless of where the Slurm binaries were found. For slurmrestd requests,
+// the query is dropped, as it contains timestamps that would never match on replay.
+func normalizeCommand(command string) string {
+	binary, args, _ := strings.Cut(strings.TrimSpace(command), " ")
+	if binary == "GET" {
+		args, _, _ = strings.Cut(args, "?")
+	}
+	if args == "" {
+		return path.Base(binary)
+	}
+	return path.Base(binary) + " " + args
+}
+
+// binaryName returns a file name friendly name for the command, used in recording file names
+func binaryName(command string) string {
+	name := strings.Fields(normalizeCommand(command))
+	if len(name) == 0 {
+		return "unknown"
+	}
+	if name[0] == "GET" && len(name) > 1 {
+		// For slurmrestd, the endpoint is more telling, e.g. 'slurm_v0.0.41_nodes'
+		name[0] = strings.Trim(name[1], "/")
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name[0])
+}
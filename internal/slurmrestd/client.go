@@ -0,0 +1,123 @@
+package slurmrestd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/antvirf/stui/internal/recorder"
+)
+
+// Client is a minimal read-only client for the slurmrestd REST API.
+// It deliberately does not depend on any other stui packages apart from the recorder,
+// so that both the config checks and the model fetchers can use it.
+type Client struct {
+	BaseURL    string
+	APIVersion string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a new slurmrestd client for the given base URL, API version (e.g. 'v0.0.41')
+// and JWT token.
+func NewClient(baseURL, apiVersion, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		APIVersion: apiVersion,
+		Token:      token,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// SlurmPath returns the full path of an endpoint under the 'slurm' API, e.g. '/slurm/v0.0.41/nodes'
+func (c *Client) SlurmPath(endpoint string) string {
+	return fmt.Sprintf("/slurm/%s/%s", c.APIVersion, strings.TrimLeft(endpoint, "/"))
+}
+
+// SlurmdbPath returns the full path of an endpoint under the 'slurmdb' API, e.g. '/slurmdb/v0.0.41/jobs'
+func (c *Client) SlurmdbPath(endpoint string) string {
+	return fmt.Sprintf("/slurmdb/%s/%s", c.APIVersion, strings.TrimLeft(endpoint, "/"))
+}
+
+// Get performs a GET request against the given path and returns the raw response body.
+// Errors reported by slurmrestd in the response body are returned as errors.
+func (c *Client) Get(ctx context.Context, path string) ([]byte, error) {
+	return recorder.Capture("GET "+path, func() ([]byte, error) {
+		return c.get(ctx, path)
+	})
+}
+
+func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("X-SLURM-USER-TOKEN", c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if msg := errorsFromBody(body); msg != "" {
+			return nil, fmt.Errorf("slurmrestd returned %s: %s", resp.Status, msg)
+		}
+		return nil, fmt.Errorf("slurmrestd returned %s", resp.Status)
+	}
+	return body, nil
+}
+
+// GetJSON performs a GET request against the given path and decodes the response into a generic map.
+func (c *Client) GetJSON(ctx context.Context, path string) (map[string]any, error) {
+	body, err := c.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode slurmrestd response: %v", err)
+	}
+	if msg := errorsFromBody(body); msg != "" {
+		return nil, fmt.Errorf("slurmrestd error: %s", msg)
+	}
+	return out, nil
+}
+
+// errorsFromBody extracts the 'errors' list that slurmrestd includes in its responses,
+// returning an empty string if there are none.
+func errorsFromBody(body []byte) string {
+	var parsed struct {
+		Errors []struct {
+			Description string `json:"description"`
+			Error       string `json:"error"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return ""
+	}
+
+	var messages []string
+	for _, e := range parsed.Errors {
+		switch {
+		case e.Description != "":
+			messages = append(messages, e.Description)
+		case e.Error != "":
+			messages = append(messages, e.Error)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
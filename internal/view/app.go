@@ -18,7 +18,9 @@ const (
 	SACCTMGR_PAGE = "sacctmgr"
 	SACCT_PAGE    = "sacct"
 	SDIAG_PAGE    = "sdiag"
+	TASKS_PAGE    = "tasks"
 	COMMAND_PAGE  = "command_modal"
+	CONFIRM_PAGE  = "confirm_modal"
 )
 
 type App struct {
@@ -26,6 +28,7 @@ type App struct {
 	Pages               *tview.Pages
 	PagesContainer      *tview.Flex  // Container for pages with border title
 	startTime           time.Time    // Start time of the application
+	screen              tcell.Screen // Set on each draw, e.g. to copy to the clipboard with OSC 52
 	CurrentTableView    *tview.Table // Points to current table view
 	FirstRenderComplete bool
 
@@ -39,6 +42,7 @@ type App struct {
 	HeaderLineOne   *tview.TextView
 	HeaderLineTwo   *tview.TextView
 	HeaderLineThree *tview.TextView
+	HeaderSummary   *tview.TextView // Summary statistics of the current view
 
 	// Current tab indicators
 	TabNodesBox         *tview.TextView
@@ -46,6 +50,7 @@ type App struct {
 	TabSchedulerBox     *tview.TextView
 	TabAccountingMgrBox *tview.TextView
 	TabAccountingBox    *tview.TextView
+	TabTasksBox         *tview.TextView
 
 	// Dropdown selectors
 	PartitionSelector      *tview.DropDown
@@ -53,6 +58,14 @@ type App struct {
 	NodeStateSelector      *tview.DropDown
 	JobStateSelector       *tview.DropDown
 	SortSelector           *tview.DropDown
+	sortSelectorThenBy     bool // Whether the sort selector adds a secondary sort column
+
+	// Sacct query inputs
+	SacctTimeRangeInput *tview.InputField
+	SacctFiltersInput   *tview.InputField
+
+	// Keys pressed so far of a plugin shortcut with several keys, e.g. 'g' of 'g l'
+	pendingPluginKeys []string
 
 	// Search state
 	SearchBox     *tview.InputField
@@ -62,8 +75,16 @@ type App struct {
 
 	// Command modal state
 	CommandModalOpen bool
+	commandModalTask *model.Task // Command running in the command modal, stopped with Ctrl-C
+
+	// Set while a prompt opened with showPrompt is open, e.g. the column chooser
+	PromptOpen bool
+
+	// Closed to stop the periodic refresh, only accessed from the UI thread
+	stopRefresh chan struct{}
 
 	// Data  and providers
+	Fetcher            model.Fetcher
 	PartitionsData     *model.TableData
 	PartitionsProvider model.DataProvider[*model.TableData]
 	NodesProvider      model.DataProvider[*model.TableData]
@@ -71,12 +92,15 @@ type App struct {
 	SacctMgrProvider   model.DataProvider[*model.TableData]
 	SacctProvider      model.DataProvider[*model.TableData]
 	SdiagProvider      model.DataProvider[*model.TextData]
+	SstatProvider      *model.SstatProvider
+	TasksProvider      *model.TasksProvider
 
 	// New style views
 	NodesView    *StuiView
 	JobsView     *StuiView
 	SacctMgrView *StuiView
 	SacctView    *StuiView
+	TasksView    *StuiView
 	SchedView    *tview.TextView // Special case, text only
 }
 
@@ -88,7 +112,19 @@ func InitializeApplication() *App {
 		Pages:                   tview.NewPages(),
 		HeaderGridInnerContents: tview.NewGrid(),
 		FirstRenderComplete:     false,
+		TasksProvider:           model.NewTasksProvider(model.Tasks), // Tasks are kept when switching profiles
 	}
+	application.App.SetAfterDrawFunc(func(screen tcell.Screen) {
+		application.screen = screen
+	})
+	application.initializeProviders()
+	return &application
+}
+
+// initializeProviders creates the fetcher and all data providers for the current config
+func (a *App) initializeProviders() {
+	a.Fetcher = model.NewFetcher()
+	a.SstatProvider = model.NewSstatProvider(a.Fetcher)
 
 	// Init data providers at start - in parallel, as they all do their first fetch on initialization
 	start := time.Now()
@@ -96,31 +132,30 @@ func InitializeApplication() *App {
 	wg.Add(6)
 	go func() {
 		defer wg.Done()
-		application.PartitionsProvider = model.NewPartitionsProvider()
+		a.PartitionsProvider = model.NewPartitionsProvider(a.Fetcher)
 	}()
 	go func() {
 		defer wg.Done()
-		application.NodesProvider = model.NewNodesProvider()
+		a.NodesProvider = model.NewNodesProvider(a.Fetcher)
 	}()
 	go func() {
 		defer wg.Done()
-		application.JobsProvider = model.NewJobsProvider()
+		a.JobsProvider = model.NewJobsProvider(a.Fetcher)
 	}()
 	go func() {
 		defer wg.Done()
-		application.SdiagProvider = model.NewSdiagProvider()
+		a.SdiagProvider = model.NewSdiagProvider(a.Fetcher)
 	}()
 	go func() {
 		defer wg.Done()
-		application.SacctProvider = model.NewSacctProvider()
+		a.SacctProvider = model.NewSacctProvider(a.Fetcher)
 	}()
 	go func() {
 		defer wg.Done()
-		application.SacctMgrProvider = model.NewSacctMgrProvider()
+		a.SacctMgrProvider = model.NewSacctMgrProvider(a.Fetcher)
 	}()
 	wg.Wait()
 	logger.Printf("START: Initial data load from scheduler took %d ms", time.Since(start).Milliseconds())
-	return &application
 }
 
 func (a *App) SetupViews() {
@@ -130,6 +165,7 @@ func (a *App) SetupViews() {
 	a.SetupNodeStateSelector()
 	a.SetupJobStateSelector()
 	a.SetupSacctMgrEntitySelector()
+	a.SetupSacctQueryInputs()
 
 	{ // Header lines
 		a.HeaderLineOne = tview.NewTextView().
@@ -146,6 +182,11 @@ func (a *App) SetupViews() {
 			SetDynamicColors(true).
 			SetTextAlign(tview.AlignCenter)
 
+		a.HeaderSummary = tview.NewTextView().
+			SetDynamicColors(true).
+			SetTextAlign(tview.AlignLeft).
+			SetWrap(true)
+
 	}
 
 	{ // Current tab boxes
@@ -153,18 +194,13 @@ func (a *App) SetupViews() {
 			SetText("(1) Nodes              [scontrol]")
 		a.TabJobsBox = tview.NewTextView().
 			SetText("(2) Jobs queue         [scontrol]")
-		a.TabAccountingBox = tview.NewTextView().
-			SetText("(3) Jobs accounting    [sacct]")
-		a.TabAccountingMgrBox = tview.NewTextView().
-			SetText("(4) Accounting manager [sacctmgr]")
+		a.TabAccountingBox = tview.NewTextView()
+		a.TabAccountingMgrBox = tview.NewTextView()
 		a.TabSchedulerBox = tview.NewTextView().
 			SetText("(5) Scheduler          [sdiag]")
-
-		// If sacct disabled, blank out those rows
-		if !config.SacctEnabled {
-			a.TabAccountingBox.SetText("")
-			a.TabAccountingMgrBox.SetText("")
-		}
+		a.TabTasksBox = tview.NewTextView().
+			SetText("(6) Tasks              [commands]")
+		a.updateAccountingTabs()
 
 		// Initial selection - nodes
 		a.TabNodesBox.SetBackgroundColor(paneSelectorHighlightColor)
@@ -176,7 +212,8 @@ func (a *App) SetupViews() {
 		AddItem(a.TabJobsBox, SCND_ROW, FRST_COL, 1, 1, 1, 0, false).
 		AddItem(a.TabAccountingBox, THRD_ROW, FRST_COL, 1, 1, 1, 0, false).
 		AddItem(a.TabAccountingMgrBox, FRTH_ROW, FRST_COL, 1, 1, 1, 0, false).
-		AddItem(a.TabSchedulerBox, FFTH_ROW, FRST_COL, 1, 1, 1, 0, false)
+		AddItem(a.TabSchedulerBox, FFTH_ROW, FRST_COL, 1, 1, 1, 0, false).
+		AddItem(a.TabTasksBox, SXTH_ROW, FRST_COL, 1, 1, 1, 0, false)
 
 	a.HeaderGrid = tview.NewGrid().
 		SetColumns(-1, -2, -1).
@@ -184,10 +221,11 @@ func (a *App) SetupViews() {
 		AddItem(a.HeaderGridInnerContents, FRST_ROW, FRST_COL, 1, 1, 0, 0, false).
 		AddItem(
 			tview.NewGrid().
-				SetRows(-1, -2, -1).
+				SetRows(-1, -1, -2, -1).
 				AddItem(a.HeaderLineOne, FRST_ROW, FRST_COL, 1, 1, 0, 0, false).
-				AddItem(a.HeaderLineTwo, SCND_ROW, FRST_COL, 1, 1, 0, 0, false).
-				AddItem(a.HeaderLineThree, THRD_ROW, FRST_COL, 1, 1, 0, 0, false),
+				AddItem(a.HeaderSummary, SCND_ROW, FRST_COL, 1, 1, 0, 0, false).
+				AddItem(a.HeaderLineTwo, THRD_ROW, FRST_COL, 1, 1, 0, 0, false).
+				AddItem(a.HeaderLineThree, FRTH_ROW, FRST_COL, 1, 1, 0, 0, false),
 			FRST_ROW, SCND_COL, 1, 1, 0, 0, false).
 		AddItem(tabGrid, FRST_ROW, THRD_COL, 1, 1, 0, 0, false)
 
@@ -203,15 +241,13 @@ func (a *App) SetupViews() {
 
 	// Main grid layout, implemented with Flex
 	a.MainFlex = tview.NewFlex().SetDirection(tview.FlexRow).
-		AddItem(a.HeaderGrid, 7, 0, false).
+		AddItem(a.HeaderGrid, 8, 0, false).
 		AddItem(a.PagesContainer, 0, 1, true)
 
 	a.MainFlex.SetBorder(true).
 		SetBorderAttributes(tcell.AttrDim).
-		SetTitle(fmt.Sprintf(
-			" stui on [%s / %s / Slurm %s] ", config.ClusterName, config.SchedulerHostName, config.SchedulerSlurmVersion,
-		)).
 		SetTitleAlign(tview.AlignCenter)
+	a.updateMainTitle()
 
 	{ // Nodes View
 		a.NodesView = NewStuiView(
@@ -220,10 +256,12 @@ func (a *App) SetupViews() {
 			a.PagesContainer.SetTitle,
 			a.UpdateHeaderLineTwo,           // errors
 			a.UpdateHeaderLineOne,           // data updates notify
+			a.UpdateHeaderSummary,           // summary statistics
 			a.copyCellToClipBoard,           // func to run when a data cell is clicked
 			a.SortSelector.SetCurrentOption, // func to run when a header row is clicked
 			&a.SearchPattern,                // pointer to search string
 		)
+		a.NodesView.SetSummaryFunction(model.SummarizeNodes)
 		a.Pages.AddPage(NODES_PAGE, a.NodesView.Grid, true, true)
 	}
 
@@ -234,10 +272,12 @@ func (a *App) SetupViews() {
 			a.PagesContainer.SetTitle,
 			a.UpdateHeaderLineTwo,           // errors
 			a.UpdateHeaderLineOne,           // data updates notify
+			a.UpdateHeaderSummary,           // summary statistics
 			a.copyCellToClipBoard,           // func to run when a data cell is clicked
 			a.SortSelector.SetCurrentOption, // func to run when a header row is clicked
 			&a.SearchPattern,                // pointer to search string
 		)
+		a.JobsView.SetSummaryFunction(model.SummarizeJobs)
 		a.Pages.AddPage(JOBS_PAGE, a.JobsView.Grid, true, false)
 	}
 
@@ -251,6 +291,7 @@ func (a *App) SetupViews() {
 			a.PagesContainer.SetTitle,
 			a.UpdateHeaderLineTwo, // errors
 			a.UpdateHeaderLineOne, // data updates notify
+			a.UpdateHeaderSummary, // summary statistics
 			//a.copyToClipBoard,               // func to run when a data cell is clicked
 			a.ShowModalPopupMinimal,
 			a.SortSelector.SetCurrentOption, // func to run when a header row is clicked
@@ -264,14 +305,30 @@ func (a *App) SetupViews() {
 			a.PagesContainer.SetTitle,
 			a.UpdateHeaderLineTwo,           // errors
 			a.UpdateHeaderLineOne,           // data updates notify
+			a.UpdateHeaderSummary,           // summary statistics
 			a.copyCellToClipBoard,           // func to run when a data cell is clicked
 			a.SortSelector.SetCurrentOption, // func to run when a header row is clicked
 			&a.SearchPattern,                // pointer to search string
 		)
-
+		a.SacctView.SetSummaryFunction(model.SummarizeSacct)
 		a.Pages.AddPage(SACCT_PAGE, a.SacctView.Grid, true, false)
 	}
 
+	{ // Tasks View, commands run in the background
+		a.TasksView = NewStuiView(
+			"Tasks",
+			a.TasksProvider,
+			a.PagesContainer.SetTitle,
+			a.UpdateHeaderLineTwo,           // errors
+			a.UpdateHeaderLineOne,           // data updates notify
+			a.UpdateHeaderSummary,           // summary statistics
+			a.copyCellToClipBoard,           // func to run when a data cell is clicked
+			a.SortSelector.SetCurrentOption, // func to run when a header row is clicked
+			&a.SearchPattern,                // pointer to search string
+		)
+		a.Pages.AddPage(TASKS_PAGE, a.TasksView.Grid, true, false)
+	}
+
 	{ // Scheduler View
 		a.SchedView = tview.NewTextView()
 		a.SchedView.
@@ -291,7 +348,33 @@ func (a *App) SetupViews() {
 			a.SortSelector,
 		)
 		// Set up sort selector for first view
-		a.setupSortSelectorOptions(a.NodesProvider, a.NodesView.sortColumn)
+		a.setupSortSelectorOptions(a.NodesProvider, a.NodesView.primarySortColumn())
+	}
+}
+
+// updateMainTitle shows the current cluster, or the federated clusters, and the active profile if any, in the main title
+func (a *App) updateMainTitle() {
+	profile := ""
+	if config.ActiveProfile != "" {
+		profile = fmt.Sprintf("%s: ", config.ActiveProfile)
+	}
+	cluster := config.ClusterName
+	if config.Clusters != "" {
+		cluster = fmt.Sprintf("clusters %s", config.Clusters)
+	}
+	a.MainFlex.SetTitle(fmt.Sprintf(
+		" stui on [%s%s / %s / Slurm %s] ", profile, cluster, config.SchedulerHostName, config.SchedulerSlurmVersion,
+	))
+}
+
+// updateAccountingTabs shows the accounting tabs only if sacct is available
+func (a *App) updateAccountingTabs() {
+	if config.SacctEnabled {
+		a.TabAccountingBox.SetText("(3) Jobs accounting    [sacct]")
+		a.TabAccountingMgrBox.SetText("(4) Accounting manager [sacctmgr]")
+	} else {
+		a.TabAccountingBox.SetText("")
+		a.TabAccountingMgrBox.SetText("")
 	}
 }
 
@@ -305,6 +388,7 @@ func (a *App) StartRefresh() {
 	a.JobsView.Render()
 	a.SacctView.Render()
 	a.SacctMgrView.Render()
+	a.TasksView.Render()
 	{ // Render sdiag
 		d := a.SdiagProvider.Data()
 		a.SchedView.SetText(d.Data)
@@ -325,12 +409,22 @@ func (a *App) StartRefresh() {
 	// 2. After that, only fetch data periodically for the active pane
 	// 3. On switching panes, if the data is older than refresh interval, we trigger a background refresh
 	//    this happens in the key binds file.
+	a.startPeriodicRefresh()
+}
+
+// startPeriodicRefresh refreshes the active pane every config.RefreshInterval, until stopped with
+// stopPeriodicRefresh. Fetches run in the UI thread, so they never overlap with other UI updates.
+func (a *App) startPeriodicRefresh() {
+	stop := make(chan struct{})
+	a.stopRefresh = stop
 	go func() {
 		fetchTicker := time.NewTicker(config.RefreshInterval)
 		defer fetchTicker.Stop()
 
 		for {
 			select {
+			case <-stop:
+				return
 			case <-fetchTicker.C:
 				a.App.QueueUpdateDraw(func() {
 					switch a.GetCurrentPageName() {
@@ -342,6 +436,8 @@ func (a *App) StartRefresh() {
 						a.SacctMgrView.FetchAndRender()
 					case SACCT_PAGE:
 						a.SacctView.FetchAndRender()
+					case TASKS_PAGE:
+						a.TasksView.FetchAndRender()
 					case SDIAG_PAGE:
 						a.SdiagProvider.Fetch()
 						a.SchedView.SetText(a.SdiagProvider.Data().Data)
@@ -351,3 +447,12 @@ func (a *App) StartRefresh() {
 		}
 	}()
 }
+
+// stopPeriodicRefresh stops the refresh started by startPeriodicRefresh. A refresh that was
+// already queued still runs, after the UI update that stopped it.
+func (a *App) stopPeriodicRefresh() {
+	if a.stopRefresh != nil {
+		close(a.stopRefresh)
+		a.stopRefresh = nil
+	}
+}
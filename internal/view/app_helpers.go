@@ -12,12 +12,6 @@ import (
 	"github.com/rivo/tview"
 )
 
-const (
-	SORT_NONE = 0
-	SORT_ASC  = 1
-	SORT_DESC = -1
-)
-
 func (a *App) GetCurrentStuiView() *StuiView {
 	switch a.CurrentTableView {
 	case a.NodesView.Table:
@@ -28,6 +22,8 @@ func (a *App) GetCurrentStuiView() *StuiView {
 		return a.SacctView
 	case a.SacctMgrView.Table:
 		return a.SacctMgrView
+	case a.TasksView.Table:
+		return a.TasksView
 	default:
 		return nil
 	}
@@ -50,6 +46,8 @@ func (a *App) GetProviderForPage(page string) model.DataProvider[*model.TableDat
 		return a.SacctProvider
 	case SACCTMGR_PAGE:
 		return a.SacctMgrProvider
+	case TASKS_PAGE:
+		return a.TasksProvider
 	default:
 		return nil
 	}
@@ -114,6 +112,8 @@ func (a *App) optionalRefreshAndRenderPage(pageName string, refresh bool) {
 		}
 		a.SacctView.SetFilter(config.PartitionFilter)
 		a.SacctView.Render()
+	case TASKS_PAGE:
+		a.TasksView.FetchAndRender() // Always current, the tasks are in memory
 	case SDIAG_PAGE:
 		if refresh {
 			d := a.SdiagProvider.Data()
@@ -124,6 +124,11 @@ func (a *App) optionalRefreshAndRenderPage(pageName string, refresh bool) {
 	go a.App.QueueUpdateDraw(func() {})
 }
 
+// RenderTasksView updates the tasks view, e.g. when a task has started or ended
+func (a *App) RenderTasksView() {
+	a.TasksView.FetchAndRender()
+}
+
 func (a *App) ShowModalPopupTable(title string, table *tview.Table) {
 	a.showModalPopup(title, table, 16, 10, 0)
 }
@@ -149,7 +154,49 @@ func (a *App) ShowModalPopupMinimal(details string) {
 	a.showModalPopup("Full cell contents", detailView, 5, 10, 1)
 }
 
-func (a *App) showModalPopup(title string, primitive tview.Primitive, width int, height int, verticalPadding int) {
+// showConfirmation asks to confirm an action, e.g. running a destructive plugin command, and
+// calls onConfirm if confirmed. The text is shown as is, without color tags.
+func (a *App) showConfirmation(text string, confirmLabel string, onConfirm func()) {
+	previousFocus := a.App.GetFocus()
+	a.CommandModalOpen = true // Blocks global keybinds while open
+
+	modal := tview.NewModal().
+		SetText(tview.Escape(text)).
+		AddButtons([]string{confirmLabel, "Cancel"}).
+		SetBackgroundColor(generalBackgroundColor).
+		SetTextColor(generalTextColor).
+		SetButtonBackgroundColor(dropdownBackgroundColor).
+		SetButtonTextColor(dropdownForegroundColor).
+		SetDoneFunc(func(_ int, label string) {
+			a.CommandModalOpen = false
+			a.Pages.RemovePage(CONFIRM_PAGE)
+			a.App.SetFocus(previousFocus)
+			if label == confirmLabel {
+				onConfirm()
+			}
+		})
+	modal.SetBorderColor(modalBorderColor)
+	modal.SetFocus(1) // Default to cancel, so that Enter doesn't run anything by accident
+
+	a.Pages.AddPage(CONFIRM_PAGE, modal, true, true)
+	a.App.SetFocus(modal)
+}
+
+// showPrompt shows a form or other input in a modal, like showModalPopup, and blocks global
+// keybinds until it is closed with the returned function, which focuses the page below again
+func (a *App) showPrompt(title string, primitive tview.Primitive, width int, height int, verticalPadding int) (closePrompt func()) {
+	pageName := a.showModalPopup(title, primitive, width, height, verticalPadding)
+	a.PromptOpen = true
+	return func() {
+		a.PromptOpen = false
+		a.Pages.RemovePage(pageName)
+		_, frontPage := a.Pages.GetFrontPage()
+		a.App.SetFocus(frontPage)
+	}
+}
+
+// showModalPopup shows the primitive in a modal on top of the current page, and returns the name of the modal page
+func (a *App) showModalPopup(title string, primitive tview.Primitive, width int, height int, verticalPadding int) string {
 	modal := tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(tview.NewTextView().
@@ -209,6 +256,7 @@ func (a *App) showModalPopup(title string, primitive tview.Primitive, width int,
 			return event
 		})
 	}
+	return pageName
 }
 
 func (a *App) setActiveTab(active string) {
@@ -218,6 +266,7 @@ func (a *App) setActiveTab(active string) {
 	a.TabSchedulerBox.SetBackgroundColor(generalBackgroundColor)
 	a.TabAccountingMgrBox.SetBackgroundColor(generalBackgroundColor)
 	a.TabAccountingBox.SetBackgroundColor(generalBackgroundColor)
+	a.TabTasksBox.SetBackgroundColor(generalBackgroundColor)
 
 	// Set active color
 	switch active {
@@ -231,11 +280,42 @@ func (a *App) setActiveTab(active string) {
 		a.TabAccountingMgrBox.SetBackgroundColor(paneSelectorHighlightColor)
 	case SACCT_PAGE:
 		a.TabAccountingBox.SetBackgroundColor(paneSelectorHighlightColor)
+	case TASKS_PAGE:
+		a.TabTasksBox.SetBackgroundColor(paneSelectorHighlightColor)
+	}
+}
+
+// federated returns whether the views show several clusters
+func (a *App) federated() bool {
+	_, federated := a.Fetcher.(*model.FederatedFetcher)
+	return federated
+}
+
+// refuseCommandsOnSeveralClusters notifies the user and returns true in the federated view, where
+// commands that change nodes or jobs are not offered, as they would run on the local cluster
+func (a *App) refuseCommandsOnSeveralClusters() bool {
+	if !a.federated() {
+		return false
+	}
+	a.ShowNotification("[yellow]Commands are not available when showing several clusters[white]", 2*time.Second)
+	return true
+}
+
+// detailsFetcher returns the fetcher for details of the row at the cursor of the view. Node names
+// and job ids are not unique across clusters, so in the federated view, details are fetched from
+// the cluster in the 'Cluster' column of the row.
+func (a *App) detailsFetcher(view *StuiView) model.Fetcher {
+	if !a.federated() {
+		return a.Fetcher
+	}
+	if cluster := view.cursorRowValue(config.CLUSTER_COLUMN); cluster != "" {
+		return &model.CliFetcher{Cluster: cluster}
 	}
+	return a.Fetcher
 }
 
 func (a *App) ShowNodeDetails(nodeName string) {
-	details, err := model.GetNodeDetailsWithTimeout(nodeName, config.RequestTimeout)
+	details, err := a.detailsFetcher(a.NodesView).NodeDetails(nodeName, config.RequestTimeout)
 	if err != nil {
 		details = fmt.Sprintf("Error fetching node details:\n%s", err.Error())
 	}
@@ -243,7 +323,7 @@ func (a *App) ShowNodeDetails(nodeName string) {
 }
 
 func (a *App) ShowJobDetails(jobID string) {
-	details, err := model.GetJobDetailsWithTimeout(jobID, config.RequestTimeout)
+	details, err := a.detailsFetcher(a.JobsView).JobDetails(jobID, config.RequestTimeout)
 	if err != nil {
 		details = fmt.Sprintf("Error fetching job details:\n%s", err.Error())
 	}
@@ -251,7 +331,7 @@ func (a *App) ShowJobDetails(jobID string) {
 }
 
 func (a *App) ShowSacctJobDetails(jobID string) {
-	details, err := model.GetSacctJobDetailsWithTimeout(jobID, config.RequestTimeout)
+	details, err := a.detailsFetcher(a.SacctView).SacctJobDetails(jobID, config.RequestTimeout)
 	if err != nil {
 		details = fmt.Sprintf("Error fetching job details:\n%s", err.Error())
 	}
@@ -2,8 +2,12 @@ package view
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/logger"
 	"github.com/tiagomelo/go-clipboard/clipboard"
 )
 
@@ -12,14 +16,88 @@ func (a *App) copyCellToClipBoard(text string) {
 }
 
 func (a *App) copyToClipBoard(text string, success string) {
-	c := clipboard.New()
-	if err := c.CopyText(text); err != nil {
+	backend, err := copyText(text, a.screen)
+	if err != nil {
 		a.ShowNotification(
-			"[red]FAIL - clipboard not available, install libx11-dev / xorg-dev / libx11-devel [white]",
-			2*time.Second,
+			fmt.Sprintf("[red]FAIL - could not copy with '%s' clipboard: %v[white]", backend, err),
+			3*time.Second,
 		)
-	} else {
-		a.ShowNotification(success, 2*time.Second)
+		return
 	}
 
+	if backend == config.CLIPBOARD_FILE {
+		success = fmt.Sprintf("%s [green](written to %s)[white]", success, clipboardFilePath())
+	}
+	a.ShowNotification(success, 2*time.Second)
+}
+
+// clipboardSetter is the part of tcell.Screen used to copy with OSC 52
+type clipboardSetter interface {
+	SetClipboard(data []byte)
+}
+
+// clipboardBackends lists the backends to try in order. With 'auto', X11 is used if there is a
+// display, then OSC 52 in SSH sessions, where the terminal is likely on another machine, then a file.
+func clipboardBackends() []string {
+	if config.Clipboard != config.CLIPBOARD_AUTO {
+		return []string{config.Clipboard}
+	}
+	var backends []string
+	if os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != "" {
+		backends = append(backends, config.CLIPBOARD_X11)
+	}
+	if os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+		backends = append(backends, config.CLIPBOARD_OSC52)
+	}
+	return append(backends, config.CLIPBOARD_FILE)
+}
+
+// copyText copies with the first of the clipboard backends that works, and returns the backend used
+func copyText(text string, screen clipboardSetter) (backend string, err error) {
+	for _, backend = range clipboardBackends() {
+		switch backend {
+		case config.CLIPBOARD_X11:
+			err = copyWithX11(text)
+		case config.CLIPBOARD_OSC52:
+			err = copyWithOSC52(text, screen)
+		case config.CLIPBOARD_FILE:
+			err = copyToFile(text)
+		}
+		if err == nil {
+			return backend, nil
+		}
+		logger.Debugf("%s clipboard not available: %v", backend, err)
+	}
+	return backend, err
+}
+
+func copyWithX11(text string) error {
+	if err := clipboard.New().CopyText(text); err != nil {
+		return fmt.Errorf("%v, install xclip, xsel or wl-clipboard", err)
+	}
+	return nil
+}
+
+// copyWithOSC52 asks the terminal to set the clipboard, which works over SSH if the terminal
+// supports it. tcell only sends it to xterm-like terminals, and inside tmux, 'set-clipboard'
+// must be enabled to pass it on to the outer terminal.
+func copyWithOSC52(text string, screen clipboardSetter) error {
+	if screen == nil {
+		return fmt.Errorf("terminal is not ready yet")
+	}
+	screen.SetClipboard([]byte(text))
+	return nil
+}
+
+// copyToFile writes the text to a file in the config dir, replacing the previous copy
+func copyToFile(text string) error {
+	path := clipboardFilePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(text), 0o600)
+}
+
+func clipboardFilePath() string {
+	return filepath.Join(config.ConfigDirPath, config.CLIPBOARD_FILE_NAME)
 }
@@ -0,0 +1,133 @@
+package view
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScreen records what is copied with OSC 52
+type fakeScreen struct {
+	copied []string
+}
+
+func (s *fakeScreen) SetClipboard(data []byte) {
+	s.copied = append(s.copied, string(data))
+}
+
+// useClipboard sets the clipboard backend and the environment that 'auto' picks a backend from,
+// writing the 'file' backend into a temporary config dir
+func useClipboard(t *testing.T, clipboard string, env map[string]string) {
+	previousClipboard, previousConfigDir := config.Clipboard, config.ConfigDirPath
+	t.Cleanup(func() { config.Clipboard, config.ConfigDirPath = previousClipboard, previousConfigDir })
+	config.Clipboard = clipboard
+	config.ConfigDirPath = filepath.Join(t.TempDir(), "stui.d")
+
+	for _, name := range []string{"DISPLAY", "WAYLAND_DISPLAY", "SSH_TTY", "SSH_CONNECTION"} {
+		t.Setenv(name, env[name])
+	}
+}
+
+func TestClipboardBackends(t *testing.T) {
+	tests := []struct {
+		name      string
+		clipboard string
+		env       map[string]string
+		backends  []string
+	}{
+		{
+			name:      "local terminal",
+			clipboard: config.CLIPBOARD_AUTO,
+			backends:  []string{config.CLIPBOARD_FILE},
+		},
+		{
+			name:      "X11 display",
+			clipboard: config.CLIPBOARD_AUTO,
+			env:       map[string]string{"DISPLAY": ":0"},
+			backends:  []string{config.CLIPBOARD_X11, config.CLIPBOARD_FILE},
+		},
+		{
+			name:      "Wayland display",
+			clipboard: config.CLIPBOARD_AUTO,
+			env:       map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+			backends:  []string{config.CLIPBOARD_X11, config.CLIPBOARD_FILE},
+		},
+		{
+			name:      "SSH session",
+			clipboard: config.CLIPBOARD_AUTO,
+			env:       map[string]string{"SSH_TTY": "/dev/pts/3"},
+			backends:  []string{config.CLIPBOARD_OSC52, config.CLIPBOARD_FILE},
+		},
+		{
+			name:      "SSH session without a tty",
+			clipboard: config.CLIPBOARD_AUTO,
+			env:       map[string]string{"SSH_CONNECTION": "10.0.0.1 50000 10.0.0.2 22"},
+			backends:  []string{config.CLIPBOARD_OSC52, config.CLIPBOARD_FILE},
+		},
+		{
+			name:      "SSH session with X11 forwarding",
+			clipboard: config.CLIPBOARD_AUTO,
+			env:       map[string]string{"DISPLAY": "localhost:10.0", "SSH_TTY": "/dev/pts/3"},
+			backends:  []string{config.CLIPBOARD_X11, config.CLIPBOARD_OSC52, config.CLIPBOARD_FILE},
+		},
+		{
+			name:      "chosen backend only",
+			clipboard: config.CLIPBOARD_OSC52,
+			env:       map[string]string{"DISPLAY": ":0"},
+			backends:  []string{config.CLIPBOARD_OSC52},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useClipboard(t, test.clipboard, test.env)
+			assert.Equal(t, test.backends, clipboardBackends())
+		})
+	}
+}
+
+func TestCopyTextToFile(t *testing.T) {
+	useClipboard(t, config.CLIPBOARD_FILE, nil)
+	screen := &fakeScreen{}
+
+	backend, err := copyText("first", screen)
+	require.NoError(t, err)
+	assert.Equal(t, config.CLIPBOARD_FILE, backend)
+
+	// Each copy replaces the previous one
+	_, err = copyText("second\nlines", screen)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(config.ConfigDirPath, config.CLIPBOARD_FILE_NAME))
+	require.NoError(t, err)
+	assert.Equal(t, "second\nlines", string(content))
+	assert.Empty(t, screen.copied)
+
+	info, err := os.Stat(clipboardFilePath())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestCopyTextWithOSC52(t *testing.T) {
+	useClipboard(t, config.CLIPBOARD_AUTO, map[string]string{"SSH_TTY": "/dev/pts/3"})
+	screen := &fakeScreen{}
+
+	backend, err := copyText("node1,node2", screen)
+	require.NoError(t, err)
+	assert.Equal(t, config.CLIPBOARD_OSC52, backend)
+	assert.Equal(t, []string{"node1,node2"}, screen.copied)
+	assert.NoFileExists(t, clipboardFilePath())
+
+	// Before the first draw, there is no screen yet, and the file is used instead
+	backend, err = copyText("node1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, config.CLIPBOARD_FILE, backend)
+	assert.FileExists(t, clipboardFilePath())
+
+	config.Clipboard = config.CLIPBOARD_OSC52
+	backend, err = copyText("node1", nil)
+	assert.Equal(t, config.CLIPBOARD_OSC52, backend)
+	assert.ErrorContains(t, err, "terminal is not ready yet")
+}
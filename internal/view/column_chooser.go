@@ -0,0 +1,212 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/model"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const COLUMN_CHOOSER_HELP = "[::b]Space[::-] show/hide  [::b]K/J[::-] move up/down  [::b]/[::-] combine with next or split  [::b]+[::-] full width  [::b]Enter[::-] apply  [::b]Esc[::-] cancel"
+
+// columnChoice is one entry in the column chooser, either a single field or several combined ('//')
+type columnChoice struct {
+	fields    []string
+	shown     bool
+	fullWidth bool
+}
+
+func (c columnChoice) rawName() string {
+	raw := strings.Join(c.fields, "//")
+	if c.fullWidth {
+		raw += "++"
+	}
+	return raw
+}
+
+// columnChooserTarget holds what the column chooser needs to know about each view
+type columnChooserTarget struct {
+	view        *StuiView
+	provider    model.DataProvider[*model.TableData]
+	columns     *[]config.ColumnConfig
+	fixedCount  int
+	available   []string
+	applyConfig func(string) error
+}
+
+func (a *App) columnChooserTargetForPage(page string) (columnChooserTarget, bool) {
+	switch page {
+	case NODES_PAGE:
+		return columnChooserTarget{a.NodesView, a.NodesProvider, config.NodeViewColumns,
+			len(strings.Split(config.FIXED_NODE_VIEW_COLUMNS, ",")), config.AvailableNodeViewColumns, config.SetNodeViewColumns}, true
+	case JOBS_PAGE:
+		return columnChooserTarget{a.JobsView, a.JobsProvider, config.JobViewColumns,
+			len(strings.Split(config.FIXED_JOB_VIEW_COLUMNS, ",")), config.AvailableJobViewColumns, config.SetJobViewColumns}, true
+	case SACCT_PAGE:
+		return columnChooserTarget{a.SacctView, a.SacctProvider, config.SacctViewColumns,
+			len(strings.Split(config.FIXED_SACCT_VIEW_COLUMNS, ",")), config.AvailableSacctViewColumns, config.SetSacctViewColumns}, true
+	}
+	return columnChooserTarget{}, false
+}
+
+// newColumnChoices lists the current columns of a view first, followed by all other available fields
+func newColumnChoices(columns []config.ColumnConfig, available []string) []columnChoice {
+	var choices []columnChoice
+	var used []string
+	for _, col := range columns {
+		fields := strings.Split(strings.ReplaceAll(col.RawName, "++", ""), "//")
+		choices = append(choices, columnChoice{fields: fields, shown: true, fullWidth: col.FullWidthColumn})
+		used = append(used, fields...)
+	}
+	for _, field := range available {
+		if !slices.Contains(used, field) {
+			choices = append(choices, columnChoice{fields: []string{field}})
+		}
+	}
+	return choices
+}
+
+// ShowColumnChooser opens a modal to choose, reorder and combine the columns of the current view.
+// Changes are applied to the view immediately, and data is fetched again with the new columns.
+func (a *App) ShowColumnChooser() {
+	target, ok := a.columnChooserTargetForPage(a.GetCurrentPageName())
+	if !ok {
+		return
+	}
+	choices := newColumnChoices((*target.columns)[target.fixedCount:], target.available)
+
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetSelectedStyle(tcell.StyleDefault.Background(rowCursorColorBackground).Foreground(rowCursorColorForeground))
+	table.SetBackgroundColor(generalBackgroundColor)
+	render := func(selectRow int) {
+		table.Clear()
+		for i, choice := range choices {
+			checkbox := "[ ]"
+			if choice.shown {
+				checkbox = "[x]"
+			}
+			modifiers := ""
+			if choice.fullWidth {
+				modifiers = " (full width)"
+			}
+			table.SetCell(i, 0, tview.NewTableCell(tview.Escape(checkbox)).SetTextColor(generalTextColor))
+			table.SetCell(i, 1, tview.NewTableCell(strings.Join(choice.fields, " // ")+modifiers).
+				SetTextColor(generalTextColor).
+				SetExpansion(1))
+		}
+		table.Select(selectRow, 0)
+	}
+	render(0)
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText(COLUMN_CHOOSER_HELP)
+	content := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(help, 1, 0, false)
+
+	closeChooser := a.showPrompt(
+		fmt.Sprintf("Columns: %s", target.view.titleHeader), content, 8, 10, 0,
+	)
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := table.GetSelection()
+		switch event.Key() {
+		case tcell.KeyEsc:
+			closeChooser()
+			return nil
+		case tcell.KeyEnter:
+			rawColumns, err := columnChoicesConfig(choices)
+			if err != nil {
+				a.ShowNotification(fmt.Sprintf("[red]Failed to apply columns: %v[white]", err), 3*time.Second)
+				return nil
+			}
+			closeChooser()
+			a.applyColumnChoices(target, rawColumns)
+			return nil
+		}
+
+		switch event.Rune() {
+		case ' ':
+			choices[row].shown = !choices[row].shown
+			render(row)
+		case '+':
+			choices[row].fullWidth = !choices[row].fullWidth
+			render(row)
+		case 'K':
+			if row > 0 {
+				choices[row-1], choices[row] = choices[row], choices[row-1]
+				render(row - 1)
+			}
+		case 'J':
+			if row < len(choices)-1 {
+				choices[row], choices[row+1] = choices[row+1], choices[row]
+				render(row + 1)
+			}
+		case '/':
+			choices = combineOrSplitColumnChoice(choices, row)
+			render(row)
+		default:
+			return event
+		}
+		return nil
+	})
+}
+
+// combineOrSplitColumnChoice splits a combined column into separate columns shown in the same place,
+// or combines a single column with the next one
+func combineOrSplitColumnChoice(choices []columnChoice, row int) []columnChoice {
+	if len(choices[row].fields) > 1 {
+		var split []columnChoice
+		for _, field := range choices[row].fields {
+			split = append(split, columnChoice{fields: []string{field}, shown: choices[row].shown})
+		}
+		return slices.Replace(choices, row, row+1, split...)
+	}
+	if row < len(choices)-1 {
+		choices[row].fields = append(choices[row].fields, choices[row+1].fields...)
+		choices[row].shown = true
+		return slices.Delete(choices, row+1, row+2)
+	}
+	return choices
+}
+
+// columnChoicesConfig returns the column config line of the shown columns, in the format of
+// e.g. '-node-columns-config'. At least one column must be shown in addition to the fixed ones.
+func columnChoicesConfig(choices []columnChoice) (string, error) {
+	var rawColumns []string
+	for _, choice := range choices {
+		if choice.shown {
+			rawColumns = append(rawColumns, choice.rawName())
+		}
+	}
+	if len(rawColumns) == 0 {
+		return "", errors.New("no columns chosen, at least one must be shown")
+	}
+	return strings.Join(rawColumns, ","), nil
+}
+
+// applyColumnChoices sets the new column config for the view, and fetches data again.
+// Sorting is cleared, as column positions may have changed.
+func (a *App) applyColumnChoices(target columnChooserTarget, rawColumns string) {
+	if err := target.applyConfig(rawColumns); err != nil {
+		a.ShowNotification(fmt.Sprintf("[red]Failed to apply columns: %v[white]", err), 3*time.Second)
+		return
+	}
+
+	target.view.sortBy(-1)
+	a.ShowNotification("[green]Loading data with new columns[white]", 2*time.Second)
+	go a.App.QueueUpdateDraw(func() {
+		target.view.FetchAndRender()
+		a.setupSortSelectorOptions(target.provider, target.view.primarySortColumn())
+	})
+}
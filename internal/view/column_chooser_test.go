@@ -0,0 +1,87 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewColumnChoices(t *testing.T) {
+	columns := []config.ColumnConfig{
+		{RawName: "CPULoad//CPUAlloc//CPUTot", DividedByColumn: true},
+		{RawName: "CfgTRES++", FullWidthColumn: true},
+		{RawName: "Reason"},
+	}
+	available := []string{"CPULoad", "CPUAlloc", "CPUTot", "CfgTRES", "Reason", "Arch", "Weight"}
+
+	assert.Equal(t, []columnChoice{
+		{fields: []string{"CPULoad", "CPUAlloc", "CPUTot"}, shown: true},
+		{fields: []string{"CfgTRES"}, shown: true, fullWidth: true},
+		{fields: []string{"Reason"}, shown: true},
+		{fields: []string{"Arch"}},
+		{fields: []string{"Weight"}},
+	}, newColumnChoices(columns, available))
+}
+
+func TestColumnChoiceRawName(t *testing.T) {
+	assert.Equal(t, "Reason", columnChoice{fields: []string{"Reason"}}.rawName())
+	assert.Equal(t, "AllocMem//RealMemory", columnChoice{fields: []string{"AllocMem", "RealMemory"}}.rawName())
+	assert.Equal(t, "CfgTRES++", columnChoice{fields: []string{"CfgTRES"}, fullWidth: true}.rawName())
+	assert.Equal(t, "AllocMem//RealMemory++", columnChoice{fields: []string{"AllocMem", "RealMemory"}, fullWidth: true}.rawName())
+}
+
+func TestCombineOrSplitColumnChoice(t *testing.T) {
+	choices := []columnChoice{
+		{fields: []string{"AllocMem"}, shown: true},
+		{fields: []string{"RealMemory"}},
+		{fields: []string{"Reason"}, shown: true},
+	}
+
+	// Combined columns are shown, even if the column combined into it was hidden
+	choices = combineOrSplitColumnChoice(choices, 0)
+	assert.Equal(t, []columnChoice{
+		{fields: []string{"AllocMem", "RealMemory"}, shown: true},
+		{fields: []string{"Reason"}, shown: true},
+	}, choices)
+
+	// The last column has nothing to combine with
+	choices = combineOrSplitColumnChoice(choices, 1)
+	assert.Len(t, choices, 2)
+
+	choices[0].shown = false
+	choices = combineOrSplitColumnChoice(choices, 0)
+	assert.Equal(t, []columnChoice{
+		{fields: []string{"AllocMem"}},
+		{fields: []string{"RealMemory"}},
+		{fields: []string{"Reason"}, shown: true},
+	}, choices)
+}
+
+func TestColumnChoicesConfig(t *testing.T) {
+	choices := []columnChoice{
+		{fields: []string{"CPUAlloc", "CPUTot"}, shown: true},
+		{fields: []string{"Arch"}},
+		{fields: []string{"CfgTRES"}, shown: true, fullWidth: true},
+	}
+	rawColumns, err := columnChoicesConfig(choices)
+	require.NoError(t, err)
+	assert.Equal(t, "CPUAlloc//CPUTot,CfgTRES++", rawColumns)
+
+	// The config line round-trips through the column config parser
+	require.NoError(t, config.SetNodeViewColumns(rawColumns))
+	t.Cleanup(config.ComputeConfigurations)
+	available := []string{"CPUAlloc", "CPUTot", "Arch", "CfgTRES"}
+	fixedCount := len(*config.NodeViewColumns) - 2
+	assert.Equal(t,
+		[]columnChoice{choices[0], choices[2], choices[1]},
+		newColumnChoices((*config.NodeViewColumns)[fixedCount:], available),
+	)
+
+	for i := range choices {
+		choices[i].shown = false
+	}
+	_, err = columnChoicesConfig(choices)
+	assert.EqualError(t, err, "no columns chosen, at least one must be shown")
+}
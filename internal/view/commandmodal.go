@@ -1,57 +1,254 @@
 package view
 
 import (
-	"context"
 	"fmt"
-	"os/exec"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/model"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-func (a *App) executeCommand(output *tview.TextView, cmdText string, pageName string) {
-	output.SetText("\n\n")
+// CommandOptions changes how the command modal runs a command, e.g. as configured for a plugin
+type CommandOptions struct {
+	ExecuteImmediately bool
+	CloseAfterExecute  bool
+	Background         bool          // Run without the modal, listed in the tasks view
+	Timeout            time.Duration // No timeout if not set
+	Env                map[string]string
+	Workdir            string
+	Targets            []string // IDs of the selected rows, recorded in the audit log
+	Confirmation       string   // Asked before running the command if set, e.g. "Run 'Drain node'?"
+}
 
-	// Execute command
-	ctx, cancel := context.WithTimeout(context.Background(), config.RequestTimeout)
-	defer cancel()
+func (o CommandOptions) taskOptions() model.TaskOptions {
+	options := model.TaskOptions{Timeout: o.Timeout, Env: o.Env, Targets: o.Targets, Confirmation: o.Confirmation}
+	if o.Workdir != "" {
+		options.Workdir = expandHomeDir(o.Workdir)
+	}
+	return options
+}
 
-	cmd := exec.CommandContext(ctx, "bash", "-c", cmdText)
-	cmdOut, err := cmd.CombinedOutput()
-	if err != nil {
-		output.SetText(output.GetText(true) + "Error: " + err.Error() + "\n")
-		output.SetText(output.GetText(true) + string(cmdOut))
-	} else {
-		commandOutput := string(cmdOut)
-		if commandOutput == "" {
-			output.SetText(output.GetText(true) + "Command executed successfully (no output)")
-		} else {
-			output.SetText(output.GetText(true) + commandOutput)
+// commandModal is an open command modal, showing the output of its task as it arrives
+type commandModal struct {
+	app           *App
+	input         *tview.InputField
+	output        *tview.TextView
+	pageName      string // Page the command is run for, refreshed after success
+	returnPage    string // Page shown once the modal is closed
+	options       CommandOptions
+	previousFocus tview.Primitive
+	task          *model.Task
+	open          bool
+	renderedText  string // Last text of the output, so that unchanged output isn't set again
+
+	// Called as well once the task is in the background, to notify when it ends
+	backgroundUpdate func(*model.Task)
+}
+
+func (m *commandModal) execute(cmdText string) {
+	if m.task != nil && m.task.Status() == model.TASK_STATUS_RUNNING {
+		return // One command at a time, Ctrl-C stops the running one
+	}
+	task := model.StartTask(cmdText, m.pageName, m.options.taskOptions(), m.onTaskUpdate)
+	model.Tasks.Add(task)
+	m.attach(task)
+	m.app.RenderTasksView()
+}
+
+// attach shows the output of the task in the modal, e.g. for tasks opened from the tasks view
+func (m *commandModal) attach(task *model.Task) {
+	m.task = task
+	m.app.commandModalTask = task
+	m.render()
+}
+
+// onTaskUpdate is called from the goroutines of the task, at most every model.TASK_UPDATE_INTERVAL
+// while it runs, so the modal is updated in the UI thread
+func (m *commandModal) onTaskUpdate(task *model.Task) {
+	go m.app.App.QueueUpdateDraw(func() {
+		if task != m.task {
+			return // An earlier command of the same modal
+		}
+		if m.open {
+			m.render()
+		}
+		if m.backgroundUpdate != nil {
+			m.backgroundUpdate(task)
+		}
+	})
+}
+
+func (m *commandModal) render() {
+	status := m.task.Status()
+	text := "\n\n" + m.task.Output()
+	switch status {
+	case model.TASK_STATUS_RUNNING:
+		if text == m.renderedText {
+			return
+		}
+		m.renderedText = text
+		m.output.SetText(text)
+		m.output.ScrollToEnd()
+		return
+	case model.TASK_STATUS_COMPLETED:
+		if m.task.Output() == "" {
+			text += "Command executed successfully (no output)"
+		}
+	case model.TASK_STATUS_FAILED:
+		if code := m.task.ExitCode(); code >= 0 {
+			text += fmt.Sprintf("\nError: exit status %d\n", code)
 		}
+	case model.TASK_STATUS_CANCELLED:
+		text += "\nStopped with Ctrl-C\n"
+	}
+	m.renderedText = text
+	m.output.SetText(text)
+	m.output.ScrollToEnd()
 
+	// Finished tasks are rendered once more for every output update that arrived before the end
+	if m.app.commandModalTask != m.task {
+		return
+	}
+	m.app.commandModalTask = nil
+	m.app.RenderTasksView()
+
+	// Tasks in the background refresh their page themselves
+	if status == model.TASK_STATUS_COMPLETED && m.backgroundUpdate == nil {
 		// After a successful command...
 		// ... clear the user's selection within the current view
-		a.ClearSelectionFromCurrentView()
+		m.app.ClearSelectionFromCurrentView()
 
 		// ... and trigger a table view refresh in the background
-		a.RefreshAndRenderPage(pageName)
+		m.app.RefreshAndRenderPage(m.pageName)
+	}
+	if m.options.CloseAfterExecute {
+		m.close()
+		m.app.ShowNotification(taskEndedNotification(m.task), 3*time.Second)
+	}
+}
 
+// close closes the modal. A command that is still running is moved to the background.
+func (m *commandModal) close() {
+	m.open = false
+	m.app.commandModalTask = nil
+	m.app.CloseCommandModal(COMMAND_PAGE, m.returnPage, m.previousFocus)
+
+	if m.task == nil || m.task.Status() != model.TASK_STATUS_RUNNING || m.backgroundUpdate != nil {
+		return
 	}
+	m.backgroundUpdate = m.app.backgroundTaskUpdate(m.task)
+	m.app.ShowNotification(
+		fmt.Sprintf("[yellow]Command still running as task %d, see the tasks view[white]", m.task.ID),
+		3*time.Second,
+	)
 }
 
 func (a *App) ShowStandardCommandModal(command string, selectedMap map[string]bool, pageName string) {
-	var selected []string
+	var selected, targets []string
 	for entry := range selectedMap {
 		selected = append(selected, entry)
 	}
+	slices.Sort(selected)
+	for _, entry := range selected {
+		targets = append(targets, strings.TrimSpace(entry))
+	}
 	command = fmt.Sprintf("%s%s ", command, strings.Join(selected, ","))
-	a.ShowCommandModal(command, pageName, false, false)
+	a.ShowCommandModal(command, pageName, CommandOptions{Targets: targets})
+}
+
+func (a *App) ShowCommandModal(command string, pageName string, options CommandOptions) {
+	if options.Confirmation != "" {
+		a.showConfirmation(fmt.Sprintf("%s\n\n%s", options.Confirmation, command), "Run", func() {
+			a.showCommandModal(command, pageName, options)
+		})
+		return
+	}
+	a.showCommandModal(command, pageName, options)
+}
+
+func (a *App) showCommandModal(command string, pageName string, options CommandOptions) {
+	if options.Background {
+		a.startBackgroundTask(command, pageName, options)
+		return
+	}
+	m := a.newCommandModal(command, pageName, options)
+	if options.ExecuteImmediately {
+		m.execute(command)
+		a.App.SetFocus(m.output)
+	}
 }
 
-func (a *App) ShowCommandModal(command string, pageName string, executeImmediately bool, closeAfterExecute bool) {
+// ShowTaskOutput opens the command modal for a task of the tasks view, streaming its output if it
+// is still running
+func (a *App) ShowTaskOutput(taskId string) {
+	task := taskForId(taskId)
+	if task == nil {
+		return
+	}
+	m := a.newCommandModal(task.Command, task.Page, CommandOptions{ExecuteImmediately: true})
+	m.returnPage = TASKS_PAGE
+	m.attach(task)
+	m.backgroundUpdate = a.backgroundTaskUpdate(task)
+	task.SetOnUpdate(m.onTaskUpdate)
+	a.App.SetFocus(m.output)
+}
+
+// RerunTask runs the command of a task of the tasks view again, as a new task with the same
+// options. It is always confirmed first, with the question of the original command if it had one.
+func (a *App) RerunTask(taskId string) {
+	task := taskForId(taskId)
+	if task == nil {
+		return
+	}
+	options := CommandOptions{
+		ExecuteImmediately: true,
+		Timeout:            task.Options.Timeout,
+		Env:                task.Options.Env,
+		Workdir:            task.Options.Workdir,
+		Targets:            task.Options.Targets,
+		Confirmation:       task.Options.Confirmation,
+	}
+	question := options.Confirmation
+	if question == "" {
+		question = "Run again?"
+	}
+	a.showConfirmation(fmt.Sprintf("%s\n\n%s", question, task.Command), "Run", func() {
+		m := a.newCommandModal(task.Command, task.Page, options)
+		m.returnPage = TASKS_PAGE
+		m.execute(task.Command)
+		a.App.SetFocus(m.output)
+	})
+}
+
+// CancelTasks stops the running tasks with the given ids
+func (a *App) CancelTasks(taskIds []string) {
+	stopped := 0
+	for _, taskId := range taskIds {
+		if task := taskForId(taskId); task != nil && task.Status() == model.TASK_STATUS_RUNNING {
+			task.Kill()
+			stopped++
+		}
+	}
+	if stopped == 0 {
+		a.ShowNotification("[yellow]No running tasks to cancel[white]", 2*time.Second)
+		return
+	}
+	a.ShowNotification(fmt.Sprintf("[green]Cancelled %d task(s)[white]", stopped), 2*time.Second)
+}
+
+func taskForId(taskId string) *model.Task {
+	id, err := strconv.Atoi(strings.TrimSpace(taskId)) // Cells are padded to the column width
+	if err != nil {
+		return nil
+	}
+	return model.Tasks.Get(id)
+}
+
+func (a *App) newCommandModal(command string, pageName string, options CommandOptions) *commandModal {
 	a.CommandModalOpen = true
 
 	// Create input field with prefilled command
@@ -63,7 +260,7 @@ func (a *App) ShowCommandModal(command string, pageName string, executeImmediate
 		SetText(command).
 		SetFieldWidth(0)
 
-	if executeImmediately {
+	if options.ExecuteImmediately {
 		input.SetDisabled(true)
 	}
 
@@ -83,7 +280,7 @@ func (a *App) ShowCommandModal(command string, pageName string, executeImmediate
 		SetDirection(tview.FlexRow).
 		AddItem(tview.NewTextView().
 			SetTextAlign(tview.AlignCenter).
-			SetText(" Execute Command (ESC to cancel) "),
+			SetText(" Execute Command (ESC to close, running commands continue in the background, Ctrl-C to stop) "),
 			1, 0, false).
 		AddItem(flex, 0, 1, true)
 
@@ -99,35 +296,31 @@ func (a *App) ShowCommandModal(command string, pageName string, executeImmediate
 			0, 16, false).
 		AddItem(nil, 0, 1, false)
 
-	// Store current page before showing modal
-	previousFocus := a.App.GetFocus()
+	m := &commandModal{
+		app:        a,
+		input:      input,
+		output:     output,
+		pageName:   pageName,
+		returnPage: pageName,
+		options:    options,
+		// Store current page before showing modal
+		previousFocus: a.App.GetFocus(),
+		open:          true,
+	}
 
 	// Add as overlay
 	a.Pages.AddPage(COMMAND_PAGE, centered, true, true)
 	a.App.SetFocus(input)
 
-	if executeImmediately {
-		a.executeCommand(output, input.GetText(), pageName)
-		a.App.SetFocus(output)
-		if closeAfterExecute {
-			a.CloseCommandModal(COMMAND_PAGE, pageName, previousFocus)
-			a.ShowNotification(
-				fmt.Sprintf("[green]Executed command '%s'[white]", command),
-				3*time.Second,
-			)
-			return
-		}
-	}
-
 	// Set up input capture
 	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
 		case tcell.KeyEnter:
-			a.executeCommand(output, input.GetText(), pageName)
+			m.execute(input.GetText())
 			return nil
 
 		case tcell.KeyEsc:
-			a.CloseCommandModal(COMMAND_PAGE, pageName, previousFocus)
+			m.close()
 			return nil
 		}
 		return event
@@ -137,11 +330,12 @@ func (a *App) ShowCommandModal(command string, pageName string, executeImmediate
 	output.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
 		case tcell.KeyEsc:
-			a.CloseCommandModal(COMMAND_PAGE, pageName, previousFocus)
+			m.close()
 			return nil
 		}
 		return event
 	})
+	return m
 }
 
 func (a *App) CloseCommandModal(commandPageName string, targetPage string, previousFocus tview.Primitive) {
@@ -150,3 +344,56 @@ func (a *App) CloseCommandModal(commandPageName string, targetPage string, previ
 	a.Pages.SwitchToPage(targetPage)
 	a.App.SetFocus(previousFocus)
 }
+
+// startBackgroundTask runs the command without the command modal, listed in the tasks view
+func (a *App) startBackgroundTask(command string, pageName string, options CommandOptions) {
+	task := model.StartTask(command, pageName, options.taskOptions(), nil)
+	model.Tasks.Add(task)
+	task.SetOnUpdate(a.backgroundTaskUpdate(task))
+	a.ShowNotification(
+		fmt.Sprintf("[green]Started task %d in the background, see the tasks view[white]", task.ID),
+		3*time.Second,
+	)
+	a.RenderTasksView()
+}
+
+// backgroundTaskUpdate returns the update function of tasks in the background, which notifies
+// when the task ends, and refreshes the page it was run from if successful
+func (a *App) backgroundTaskUpdate(task *model.Task) func(*model.Task) {
+	ended := false // Only accessed from the UI thread
+	return func(*model.Task) {
+		if task.Status() == model.TASK_STATUS_RUNNING {
+			return // Only the end of the task is notified
+		}
+		go a.App.QueueUpdateDraw(func() {
+			if ended {
+				return
+			}
+			ended = true
+			a.ShowNotification(taskEndedNotification(task), 3*time.Second)
+			if task.Status() == model.TASK_STATUS_COMPLETED {
+				a.RefreshAndRenderPage(task.Page)
+			}
+			a.RenderTasksView()
+		})
+	}
+}
+
+func taskEndedNotification(task *model.Task) string {
+	switch task.Status() {
+	case model.TASK_STATUS_COMPLETED:
+		return fmt.Sprintf("[green]Executed command '%s'[white]", task.Command)
+	case model.TASK_STATUS_FAILED:
+		return fmt.Sprintf("[red]Command '%s' failed with exit code %d[white]", task.Command, task.ExitCode())
+	}
+	return fmt.Sprintf("[red]Command '%s': %s[white]", task.Command, strings.ToLower(task.Status()))
+}
+
+// stopCommandModalTask stops the command running in the command modal, returning false if there is none
+func (a *App) stopCommandModalTask() bool {
+	if !a.CommandModalOpen || a.commandModalTask == nil {
+		return false
+	}
+	a.commandModalTask.Kill()
+	return true
+}
@@ -0,0 +1,92 @@
+package view
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/antvirf/stui/internal/logger"
+	"github.com/antvirf/stui/internal/model"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ShowExportPrompt asks for a file path and format, and writes the rows of the current view
+// to it exactly as shown, i.e. after filters, search and sorting. Unlike the clipboard, this
+// works on headless login nodes.
+func (a *App) ShowExportPrompt() {
+	view := a.GetCurrentStuiView()
+	if view == nil {
+		return
+	}
+	page := a.GetCurrentPageName()
+	format := model.EXPORT_FORMAT_CSV
+	defaultPath := fmt.Sprintf("stui-%s-%s%s", page, time.Now().Format("20060102-150405"), model.EXPORT_FILE_EXTENSIONS[format])
+
+	form := tview.NewForm().
+		SetFieldBackgroundColor(dropdownBackgroundColor).
+		SetFieldTextColor(dropdownForegroundColor).
+		SetLabelColor(generalTextColor).
+		SetButtonBackgroundColor(dropdownBackgroundColor).
+		SetButtonTextColor(dropdownForegroundColor)
+	form.SetBackgroundColor(generalBackgroundColor)
+	form.AddInputField("Path:", defaultPath, 0, nil, nil)
+	pathInput := form.GetFormItem(0).(*tview.InputField)
+
+	// Changing the format also changes the file extension, if it was the one of the previous format
+	form.AddDropDown("Format:", model.EXPORT_FORMATS, slices.Index(model.EXPORT_FORMATS, format), func(option string, _ int) {
+		path := pathInput.GetText()
+		if previous := model.EXPORT_FILE_EXTENSIONS[format]; strings.HasSuffix(path, previous) {
+			pathInput.SetText(strings.TrimSuffix(path, previous) + model.EXPORT_FILE_EXTENSIONS[option])
+		}
+		format = option
+	})
+
+	closePrompt := a.showPrompt(fmt.Sprintf("Export %s to file", page), form, 6, 4, 6)
+	export := func() {
+		closePrompt()
+		a.exportViewToFile(view, pathInput.GetText(), format)
+	}
+
+	form.AddButton("Export", export)
+	form.AddButton("Cancel", closePrompt)
+	form.SetCancelFunc(closePrompt)
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Export directly from the path input, without going through the buttons
+		if event.Key() == tcell.KeyEnter && pathInput.HasFocus() {
+			export()
+			return nil
+		}
+		return event
+	})
+}
+
+// expandHomeDir replaces a leading '~/' with the home directory
+func expandHomeDir(path string) string {
+	if rest, found := strings.CutPrefix(path, "~/"); found {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+func (a *App) exportViewToFile(view *StuiView, path string, format string) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		a.ShowNotification("[red]No file path given, nothing exported[white]", 3*time.Second)
+		return
+	}
+	path = expandHomeDir(path)
+
+	data := view.RenderedData()
+	if err := model.WriteTableDataToFile(path, data, format); err != nil {
+		a.ShowNotification(fmt.Sprintf("[red]Failed to export: %v[white]", err), 5*time.Second)
+		return
+	}
+	logger.Printf("Exported %d rows to %s", data.Length(), path)
+	a.ShowNotification(fmt.Sprintf("[green]Exported %d rows to %s[white]", data.Length(), path), 5*time.Second)
+}
@@ -23,6 +23,10 @@ func (a *App) UpdateHeaderLineTwo(v string) {
 	a.HeaderLineTwo.SetText(v)
 }
 
+func (a *App) UpdateHeaderSummary(v string) {
+	a.HeaderSummary.SetText(v)
+}
+
 // Add each given primitive as a row to the top-left header area.
 func (a *App) SetHeaderGridInnerContents(content ...tview.Primitive) {
 	a.HeaderGridInnerContents.Clear()
@@ -16,62 +16,50 @@ import (
 func (a *App) SetupKeybinds() {
 	// Global keybinds (work anywhere except when typing in search)
 	a.App.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-
-		switch event.Key() {
-		case tcell.KeyCtrlC:
-			a.App.Stop()
-			duration := time.Since(a.startTime)
-			rpm := float64(model.FetchCounter.Count) / duration.Seconds() * 60
-			rpm = min(rpm, float64(model.FetchCounter.Count))
-			logger.Printf(
-				"END: Session stats: duration=%s, total_scheduler_calls=%d, requests_per_minute=%.1f",
-				duration.Round(time.Second),
-				model.FetchCounter.Count,
-				rpm,
-			)
-			logger.Printf("Thank you for using stui!")
+		// Ctrl-C stops the command running in the command modal, otherwise it always exits. Other
+		// keys bound to quit only work outside of prompts.
+		if event.Key() == tcell.KeyCtrlC {
+			if a.stopCommandModalTask() {
+				return nil
+			}
+			a.quit()
 			return event
 		}
 
 		// Don't allow pane switching when prompts are open or selectors are in focus
 		if a.CommandModalOpen ||
+			a.PromptOpen ||
+			len(a.pendingPluginKeys) > 0 ||
 			a.SearchBox.HasFocus() ||
 			a.PartitionSelector.HasFocus() ||
-			a.SacctMgrEntitySelector.HasFocus() {
+			a.SacctMgrEntitySelector.HasFocus() ||
+			a.SacctTimeRangeInput.HasFocus() ||
+			a.SacctFiltersInput.HasFocus() {
 			return event
 		}
 
-		switch event.Rune() {
-		case '?':
+		switch config.ActionForKey(config.EventKeyName(event), config.KEYBINDING_SCOPE_GLOBAL) {
+		case config.ACTION_QUIT:
+			a.quit()
+			return nil
+		case config.ACTION_HELP:
+			// Plugins are listed if they apply to the row under the cursor
+			var row map[string]string
+			if view := a.GetCurrentStuiView(); view != nil {
+				row = a.rowData(a.GetCurrentPageName(), currentRowId(view.Table))
+			}
 			a.ShowModalPopupString(
 				"Shortcuts",
 				fmt.Sprintf(
 					"%s\n%s",
-					config.KEYBOARD_SHORTCUTS,
-					GetKeyboardShortcutHelperForPage(a.GetCurrentPageName()),
+					config.KeyboardShortcutsHelp(),
+					GetKeyboardShortcutHelperForPage(a.GetCurrentPageName(), row),
 				),
 			)
-		case '1':
-			a.SwitchToPage(NODES_PAGE)
-			a.CurrentTableView = a.NodesView.Table
-			a.SetHeaderGridInnerContents(
-				a.PartitionSelector,
-				a.NodeStateSelector,
-				a.SortSelector,
-			)
-			if a.SearchPattern != "" {
-				a.ShowSearchBox(a.NodesView.Grid)
-			} else {
-				a.HideSearchBox()
-			}
-			a.App.SetFocus(a.NodesView.Table)
-			a.setupSortSelectorOptions(a.NodesProvider, a.NodesView.sortColumn)
-			a.PagesContainer.SetTitle(a.NodesView.completeTitle)
-			go a.App.QueueUpdateDraw(func() {
-				a.NodesView.FetchIfStaleAndRender(config.RefreshInterval)
-			})
+		case config.ACTION_NODES_VIEW:
+			a.showNodesPage()
 			return nil
-		case '2':
+		case config.ACTION_JOBS_VIEW:
 			a.SwitchToPage(JOBS_PAGE)
 			a.CurrentTableView = a.JobsView.Table
 			a.SetHeaderGridInnerContents(
@@ -85,19 +73,21 @@ func (a *App) SetupKeybinds() {
 				a.HideSearchBox()
 			}
 			a.App.SetFocus(a.JobsView.Table)
-			a.setupSortSelectorOptions(a.JobsProvider, a.JobsView.sortColumn)
+			a.setupSortSelectorOptions(a.JobsProvider, a.JobsView.primarySortColumn())
 			a.PagesContainer.SetTitle(a.JobsView.completeTitle)
 			go a.App.QueueUpdateDraw(func() {
 				a.JobsView.FetchIfStaleAndRender(config.RefreshInterval)
 			})
 			return nil
-		case '3':
+		case config.ACTION_SACCT_VIEW:
 			if config.SacctEnabled {
 				a.SwitchToPage(SACCT_PAGE)
 				a.CurrentTableView = a.SacctView.Table
 				a.SetHeaderGridInnerContents(
 					a.PartitionSelector,
 					a.JobStateSelector,
+					a.SacctTimeRangeInput,
+					a.SacctFiltersInput,
 					a.SortSelector,
 				)
 				if a.SearchPattern != "" {
@@ -106,14 +96,14 @@ func (a *App) SetupKeybinds() {
 					a.HideSearchBox()
 				}
 				a.App.SetFocus(a.SacctView.Table)
-				a.setupSortSelectorOptions(a.SacctProvider, a.SacctView.sortColumn)
+				a.setupSortSelectorOptions(a.SacctProvider, a.SacctView.primarySortColumn())
 				a.PagesContainer.SetTitle(a.SacctView.completeTitle)
 				go a.App.QueueUpdateDraw(func() {
 					a.SacctView.FetchIfStaleAndRender(config.RefreshInterval)
 				})
 			}
 			return nil
-		case '4':
+		case config.ACTION_SACCTMGR_VIEW:
 			if config.SacctEnabled {
 				a.SwitchToPage(SACCTMGR_PAGE)
 				a.CurrentTableView = a.SacctMgrView.Table
@@ -127,14 +117,32 @@ func (a *App) SetupKeybinds() {
 					a.HideSearchBox()
 				}
 				a.App.SetFocus(a.SacctMgrView.Table)
-				a.setupSortSelectorOptions(a.SacctMgrProvider, a.SacctMgrView.sortColumn)
+				a.setupSortSelectorOptions(a.SacctMgrProvider, a.SacctMgrView.primarySortColumn())
 				a.PagesContainer.SetTitle(a.SacctMgrView.completeTitle)
 				go a.App.QueueUpdateDraw(func() {
 					a.SacctMgrView.FetchIfStaleAndRender(config.RefreshInterval)
 				})
 			}
 			return nil
-		case '5':
+		case config.ACTION_PROFILES:
+			a.ShowProfileSelector()
+			return nil
+		case config.ACTION_TASKS_VIEW:
+			a.SwitchToPage(TASKS_PAGE)
+			a.CurrentTableView = a.TasksView.Table
+			a.SetHeaderGridInnerContents(
+				a.SortSelector,
+			)
+			if a.SearchPattern != "" {
+				a.ShowSearchBox(a.TasksView.Grid)
+			} else {
+				a.HideSearchBox()
+			}
+			a.App.SetFocus(a.TasksView.Table)
+			a.setupSortSelectorOptions(a.TasksProvider, a.TasksView.primarySortColumn())
+			a.TasksView.FetchAndRender()
+			return nil
+		case config.ACTION_SCHEDULER_VIEW:
 			a.SwitchToPage(SDIAG_PAGE)
 			a.PagesContainer.SetTitle(" Scheduler status (sdiag) ")
 			a.CurrentTableView = nil
@@ -142,12 +150,14 @@ func (a *App) SetupKeybinds() {
 			a.SetHeaderGridInnerContents(tview.NewBox())
 			a.UpdateHeaderLineOne("")
 			a.UpdateHeaderLineTwo("")
+			a.UpdateHeaderSummary("")
 			return nil
 		}
 		return event
 	})
 
-	if config.SacctEnabled {
+	// Set up even if sacct is disabled, as switching profiles may enable it
+	{
 		a.SacctView.Table.SetInputCapture(
 			tableViewInputCapture(
 				a,
@@ -169,6 +179,15 @@ func (a *App) SetupKeybinds() {
 	}
 
 	// Table view keybinds
+	a.TasksView.Table.SetInputCapture(
+		tableViewInputCapture(
+			a,
+			a.TasksView.Table,
+			&a.TasksView.Selection,
+			"", // Used for command modal, ignored if blank
+			a.ShowTaskOutput,
+		),
+	)
 	a.NodesView.Table.SetInputCapture(
 		tableViewInputCapture(
 			a,
@@ -202,27 +221,52 @@ func tableViewInputCapture(
 		// Passing this as a pointer will cause a nil pointer dereference
 		var data *model.TableData
 		var grid *tview.Grid
+		var stuiView *StuiView
 		switch view {
 		case a.NodesView.Table:
 			data = a.NodesProvider.Data()
-			grid = a.NodesView.Grid
+			stuiView = a.NodesView
 		case a.JobsView.Table:
 			data = a.JobsProvider.Data()
-			grid = a.JobsView.Grid
+			stuiView = a.JobsView
 		case a.SacctMgrView.Table:
 			data = a.SacctMgrProvider.Data()
-			grid = a.SacctMgrView.Grid
+			stuiView = a.SacctMgrView
 		case a.SacctView.Table:
 			data = a.SacctProvider.Data()
-			grid = a.SacctView.Grid
+			stuiView = a.SacctView
+		case a.TasksView.Table:
+			data = a.TasksProvider.Data()
+			stuiView = a.TasksView
+		}
+		grid = stuiView.Grid
+
+		// Keys after the start of a plugin key sequence only go to plugins
+		if len(a.pendingPluginKeys) > 0 {
+			a.ExecutePluginForShortcut(config.EventKeyName(event), a.GetCurrentPageName(), currentRowId(view), *selection)
+			return nil
 		}
-		switch event.Rune() {
-		case '/':
+
+		// Table navigation is rebound by passing on the arrow keys that the table handles
+		switch config.ActionForKey(config.EventKeyName(event), config.KEYBINDING_SCOPE_TABLE, a.GetCurrentPageName()) {
+		case config.ACTION_MOVE_UP:
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case config.ACTION_MOVE_DOWN:
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case config.ACTION_MOVE_LEFT:
+			return tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone)
+		case config.ACTION_MOVE_RIGHT:
+			return tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone)
+		case config.ACTION_MOVE_TOP:
+			return tcell.NewEventKey(tcell.KeyHome, 0, tcell.ModNone)
+		case config.ACTION_MOVE_BOTTOM:
+			return tcell.NewEventKey(tcell.KeyEnd, 0, tcell.ModNone)
+		case config.ACTION_SEARCH:
 			a.ShowSearchBox(grid)
 			a.RenderCurrentView()
 			a.App.SetFocus(a.SearchBox) // Only focus search when / is pressed
 			return nil
-		case ' ':
+		case config.ACTION_SELECT:
 			row, _ := view.GetSelection()
 			// Certain tables in Sacctmgr have no clear ID, and the current selection implementation relies
 			// on the first column of a row to be an identifier column.
@@ -234,7 +278,8 @@ func tableViewInputCapture(
 				return nil
 			}
 			if row > 0 { // Skip header row
-				entryName := view.GetCell(row, 0).Text
+				// Keyed by cluster and id in the federated view, see model.TableData.RowKey
+				entryName := stuiView.cursorRowKey()
 
 				if (*selection)[entryName] {
 					delete(*selection, entryName)
@@ -264,15 +309,15 @@ func tableViewInputCapture(
 				}
 			}
 			return nil
-		case 'p':
+		case config.ACTION_PARTITION:
 			if a.GetCurrentPageName() == JOBS_PAGE || a.GetCurrentPageName() == NODES_PAGE || a.GetCurrentPageName() == SACCT_PAGE {
 				a.App.SetFocus(a.PartitionSelector)
 			}
-		case 'e':
+		case config.ACTION_ENTITY:
 			if a.GetCurrentPageName() == SACCTMGR_PAGE {
 				a.App.SetFocus(a.SacctMgrEntitySelector)
 			}
-		case 's':
+		case config.ACTION_STATE:
 			switch a.GetCurrentPageName() {
 			case NODES_PAGE:
 				a.App.SetFocus(a.NodeStateSelector)
@@ -281,15 +326,72 @@ func tableViewInputCapture(
 			case SACCT_PAGE:
 				a.App.SetFocus(a.JobStateSelector)
 			}
-		case 'o':
+		case config.ACTION_JOB_USAGE:
+			if view == a.JobsView.Table {
+				if len(*selection) > 0 {
+					var jobKeys []string
+					for jobKey := range *selection {
+						jobKeys = append(jobKeys, jobKey)
+					}
+					slices.Sort(jobKeys)
+					a.ShowJobUsage(jobKeys)
+				} else if jobKey := stuiView.cursorRowKey(); jobKey != "" {
+					a.ShowJobUsage([]string{jobKey})
+				}
+				return nil
+			}
+		case config.ACTION_TIME_RANGE:
+			if a.GetCurrentPageName() == SACCT_PAGE {
+				a.App.SetFocus(a.SacctTimeRangeInput)
+				return nil
+			}
+		case config.ACTION_SACCT_FILTERS:
+			if a.GetCurrentPageName() == SACCT_PAGE {
+				a.App.SetFocus(a.SacctFiltersInput)
+				return nil
+			}
+		case config.ACTION_SORT:
 			if a.GetCurrentPageName() == NODES_PAGE ||
 				a.GetCurrentPageName() == JOBS_PAGE ||
 				a.GetCurrentPageName() == SACCT_PAGE ||
-				a.GetCurrentPageName() == SACCTMGR_PAGE {
-				a.App.SetFocus(a.SortSelector)
+				a.GetCurrentPageName() == SACCTMGR_PAGE ||
+				a.GetCurrentPageName() == TASKS_PAGE {
+				a.FocusSortSelector(false)
 			}
 			return nil
-		case 'c':
+		case config.ACTION_COLUMNS:
+			a.ShowColumnChooser()
+			return nil
+		case config.ACTION_EXPORT:
+			a.ShowExportPrompt()
+			return nil
+		case config.ACTION_THEN_SORT:
+			if a.GetCurrentPageName() == NODES_PAGE ||
+				a.GetCurrentPageName() == JOBS_PAGE ||
+				a.GetCurrentPageName() == SACCT_PAGE ||
+				a.GetCurrentPageName() == SACCTMGR_PAGE ||
+				a.GetCurrentPageName() == TASKS_PAGE {
+				a.FocusSortSelector(true)
+			}
+			return nil
+		case config.ACTION_COMMAND:
+			if commandModalFilter != "" && a.refuseCommandsOnSeveralClusters() {
+				return nil
+			}
+			// Nodes get a form of common actions, which can still open the prompt
+			if view == a.NodesView.Table {
+				var nodes []string
+				for node := range *selection {
+					nodes = append(nodes, strings.TrimSpace(node))
+				}
+				if len(nodes) == 0 {
+					if rowId := currentRowId(view); rowId != "" {
+						nodes = []string{strings.TrimSpace(rowId)}
+					}
+				}
+				a.ShowNodeActionForm(nodes)
+				return nil
+			}
 			// This section is only active if there is a commandModalFilter specified.
 			if commandModalFilter != "" {
 				// If user has a selection, use the selection
@@ -308,13 +410,13 @@ func tableViewInputCapture(
 				}
 			}
 			return nil
-		case 'y':
+		case config.ACTION_COPY:
 			if len(*selection) > 0 && data != nil {
 				var sb strings.Builder
 				for entryName := range *selection {
 					// Find the node in our table data
 					for _, row := range data.Rows {
-						if row[0] == entryName {
+						if data.RowKey(row) == entryName {
 							if config.CopyFirstColumnOnly {
 								sb.WriteString(row[0])
 							} else {
@@ -328,27 +430,47 @@ func tableViewInputCapture(
 				a.copyToClipBoard(sb.String(), "[green]Copied row details clipboard[white]")
 				return nil
 			}
-		}
-
-		switch event.Key() {
-		case tcell.KeyEnter:
+		case config.ACTION_DETAILS:
 			row, _ := view.GetSelection()
 			if row > 0 { // Skip header row
 				entryName := view.GetCell(row, 0).Text
 				detailsFunction(entryName)
 				return nil
 			}
-		case tcell.KeyCtrlR:
+		case config.ACTION_RERUN_TASK:
+			if view == a.TasksView.Table {
+				if rowId := currentRowId(view); rowId != "" {
+					a.RerunTask(rowId)
+				}
+				return nil
+			}
+		case config.ACTION_CANCEL_TASK:
+			if view == a.TasksView.Table {
+				var taskIds []string
+				for taskId := range *selection {
+					taskIds = append(taskIds, taskId)
+				}
+				if len(taskIds) == 0 {
+					taskIds = []string{currentRowId(view)}
+				}
+				a.CancelTasks(taskIds)
+				return nil
+			}
+		case config.ACTION_REFRESH:
 			// Manual refresh of currently visible view
 			a.optionalRefreshAndRenderCurrentView(true)
-			a.ShowNotification("[green]Ctrl+R: Manual data refresh[white]", 1*time.Second)
-		case tcell.KeyCtrlD:
+			a.ShowNotification("[green]Manual data refresh[white]", 1*time.Second)
+			return nil
+		case config.ACTION_CANCEL_JOB:
 			row, _ := view.GetSelection()
 			if row == 0 { // Skip if user is on header row / there is on data
 				return nil
 			}
 			// The below is an ugly way to check that we're in the jobs view
 			if strings.Contains(commandModalFilter, "JobId") {
+				if a.refuseCommandsOnSeveralClusters() {
+					return nil
+				}
 				SCANCEL_COMMAND := "scancel "
 				// If user has a selection, use the selection
 				if len(*selection) > 0 {
@@ -365,6 +487,9 @@ func tableViewInputCapture(
 				}
 			}
 			return nil
+		}
+
+		switch event.Key() {
 		case tcell.KeyEsc:
 			if a.SearchActive {
 				a.HideSearchBox()
@@ -374,12 +499,55 @@ func tableViewInputCapture(
 		default:
 			// In case nothing else matched, perhaps its defined in a plugin.
 			// Get the current row and pass it in.
-			row, _ := view.GetSelection()
-			if row > 0 {
-				rowId := view.GetCell(row, 0).Text
-				a.ExecutePluginForShortcut(event.Key(), a.GetCurrentPageName(), rowId)
+			if a.ExecutePluginForShortcut(config.EventKeyName(event), a.GetCurrentPageName(), currentRowId(view), *selection) {
+				return nil
 			}
 		}
 		return event
 	}
 }
+
+// currentRowId returns the first column of the row under the cursor, or an empty string on the header row
+func currentRowId(view *tview.Table) string {
+	row, _ := view.GetSelection()
+	if row > 0 {
+		return view.GetCell(row, 0).Text
+	}
+	return ""
+}
+
+func (a *App) quit() {
+	a.App.Stop()
+	duration := time.Since(a.startTime)
+	rpm := float64(model.FetchCounter.Count) / duration.Seconds() * 60
+	rpm = min(rpm, float64(model.FetchCounter.Count))
+	logger.Printf(
+		"END: Session stats: duration=%s, total_scheduler_calls=%d, requests_per_minute=%.1f",
+		duration.Round(time.Second),
+		model.FetchCounter.Count,
+		rpm,
+	)
+	logger.Printf("Thank you for using stui!")
+}
+
+// showNodesPage switches to the nodes page, which is always available
+func (a *App) showNodesPage() {
+	a.SwitchToPage(NODES_PAGE)
+	a.CurrentTableView = a.NodesView.Table
+	a.SetHeaderGridInnerContents(
+		a.PartitionSelector,
+		a.NodeStateSelector,
+		a.SortSelector,
+	)
+	if a.SearchPattern != "" {
+		a.ShowSearchBox(a.NodesView.Grid)
+	} else {
+		a.HideSearchBox()
+	}
+	a.App.SetFocus(a.NodesView.Table)
+	a.setupSortSelectorOptions(a.NodesProvider, a.NodesView.primarySortColumn())
+	a.PagesContainer.SetTitle(a.NodesView.completeTitle)
+	go a.App.QueueUpdateDraw(func() {
+		a.NodesView.FetchIfStaleAndRender(config.RefreshInterval)
+	})
+}
@@ -0,0 +1,119 @@
+package view
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/antvirf/stui/internal/model"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Offered in the node action form in addition to model.NODE_ACTIONS, opens the free-form prompt
+const NODE_ACTION_CUSTOM = "Custom 'scontrol update' command"
+
+// ShowNodeActionForm asks for a node action like drain or resume for the given nodes, and shows
+// the resulting 'scontrol' command for confirmation before running it
+func (a *App) ShowNodeActionForm(nodes []string) {
+	if len(nodes) == 0 {
+		return
+	}
+	slices.Sort(nodes)
+	action := model.NodeAction{Action: model.NODE_ACTION_DRAIN, NextState: model.NODE_REBOOT_NEXT_STATES[0]}
+
+	form := tview.NewForm().
+		SetFieldBackgroundColor(dropdownBackgroundColor).
+		SetFieldTextColor(dropdownForegroundColor).
+		SetLabelColor(generalTextColor).
+		SetButtonBackgroundColor(dropdownBackgroundColor).
+		SetButtonTextColor(dropdownForegroundColor)
+	form.SetBackgroundColor(generalBackgroundColor)
+
+	// Set once the form is shown, after all fields are added
+	var closePrompt func()
+	closeForm := func() { closePrompt() }
+
+	review := func() {
+		if action.Action == NODE_ACTION_CUSTOM {
+			closeForm()
+			a.ShowStandardCommandModal("scontrol update NodeName=", selectionMap(nodes), NODES_PAGE)
+			return
+		}
+		command, err := model.NodeActionCommand(nodes, action)
+		if err != nil {
+			a.ShowNotification(fmt.Sprintf("[red]Invalid node action: %v[white]", err), 3*time.Second)
+			return
+		}
+		closeForm()
+		// The nodes view is refreshed once the command has succeeded
+		a.ShowCommandModal(command, NODES_PAGE, CommandOptions{
+			ExecuteImmediately: true,
+			Targets:            nodes,
+			Confirmation:       fmt.Sprintf("%s %d node(s)?", action.Action, len(nodes)),
+		})
+	}
+
+	// Only the fields of the chosen action are shown, after the action dropdown
+	setFields := func() {
+		for form.GetFormItemCount() > 1 {
+			form.RemoveFormItem(1)
+		}
+		switch action.Action {
+		case model.NODE_ACTION_DRAIN, model.NODE_ACTION_DOWN:
+			form.AddInputField("Reason (required):", action.Reason, 0, nil, func(text string) { action.Reason = text })
+		case model.NODE_ACTION_REBOOT:
+			form.AddInputField("Reason:", action.Reason, 0, nil, func(text string) { action.Reason = text })
+		}
+		switch action.Action {
+		case model.NODE_ACTION_DRAIN:
+			form.AddCheckbox("Reboot once idle:", action.Reboot, func(checked bool) { action.Reboot = checked })
+		case model.NODE_ACTION_REBOOT:
+			form.AddDropDown("Next state:", model.NODE_REBOOT_NEXT_STATES,
+				slices.Index(model.NODE_REBOOT_NEXT_STATES, action.NextState),
+				func(option string, _ int) { action.NextState = option })
+		case model.NODE_ACTION_SET_FEATURES:
+			form.AddInputField("Features:", action.Features, 0, nil, func(text string) { action.Features = text })
+		case model.NODE_ACTION_SET_WEIGHT:
+			form.AddInputField("Weight:", action.Weight, 0, nil, func(text string) { action.Weight = text })
+		}
+	}
+
+	actions := append(slices.Clone(model.NODE_ACTIONS), NODE_ACTION_CUSTOM)
+	form.AddDropDown("Action:", actions, 0, func(option string, _ int) {
+		if option == action.Action {
+			return
+		}
+		action.Action = option
+		setFields()
+	})
+	setFields()
+
+	form.AddButton("Review", review)
+	form.AddButton("Cancel", closeForm)
+	form.SetCancelFunc(closeForm)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Review directly from the input fields, without going through the buttons
+		if event.Key() == tcell.KeyEnter {
+			if _, isInput := a.App.GetFocus().(*tview.InputField); isInput {
+				review()
+				return nil
+			}
+		}
+		return event
+	})
+
+	// Shown once all fields are added, so that the first one is focused
+	closePrompt = a.showPrompt(fmt.Sprintf("Node action for %s", strings.Join(nodes, ",")), form, 6, 8, 4)
+}
+
+// selectionMap turns ids into a selection, as used by the command modal
+func selectionMap(ids []string) map[string]bool {
+	selection := map[string]bool{}
+	for _, id := range ids {
+		selection[id] = true
+	}
+	return selection
+}
@@ -4,50 +4,222 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/antvirf/stui/internal/config"
 	"github.com/antvirf/stui/internal/logger"
-	"github.com/gdamore/tcell/v2"
+	"github.com/antvirf/stui/internal/model"
 )
 
-const (
-	NO_KEY_FOUND = -1
-)
+// ExecutePluginForShortcut runs the plugin whose shortcut matches the key when on a particular page,
+// and returns whether the key was used. Keys that start a shortcut with several keys, e.g. 'g'
+// of 'g l', are kept until the next key.
+func (a *App) ExecutePluginForShortcut(key string, page string, rowId string, selection map[string]bool) bool {
+	sequence := append(a.pendingPluginKeys, key)
+	a.pendingPluginKeys = nil
+	row := a.rowData(page, rowId)
 
-// Exec a plugin command when on a particular page
-func (a *App) ExecutePluginForShortcut(key tcell.Key, page string, rowId string) {
-	availablePlugins := getPluginsForPage(page)
-	for _, plugin := range availablePlugins {
-		parsedKey := asKey(plugin.Shortcut)
-		if parsedKey == NO_KEY_FOUND {
-			logger.Debugf("plugin command %s has invalid shortcut: '%s'", plugin.Name, plugin.Shortcut)
+	startsSequence := false
+	for _, plugin := range getPluginsForPage(page) {
+		shortcut, err := config.ParseKeySequence(plugin.Shortcut)
+		if err != nil {
+			logger.Debugf("plugin command %s has invalid shortcut: '%s': %v", plugin.Name, plugin.Shortcut, err)
 			continue // The key is invalid so we skip this plugin
 		}
 
-		if parsedKey == key {
-			provider := a.GetProviderForPage(page)
-			if provider == nil {
-				break
-			}
+		if len(shortcut) > len(sequence) && slices.Equal(shortcut[:len(sequence)], sequence) {
+			startsSequence = true
+			continue
+		}
+		if !slices.Equal(shortcut, sequence) {
+			continue
+		}
 
-			// Get row data for this row id
-			rowData, err := provider.Data().GetRowAsMapById(rowId)
-			if err != nil {
-				logger.Printf("could not get data for this row")
-				break
+		// Plugins that don't apply to any of the rows leave the shortcut to later plugins, e.g. on
+		// the header row without a selection
+		ids, rows, err := pluginTargets(plugin, rowId, selection, a.pageData(page), a.tableOrder())
+		if err != nil {
+			a.ShowNotification(fmt.Sprintf("[red]Invalid 'when' of plugin '%s': %v[white]", plugin.Name, err), 5*time.Second)
+			return true
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		// Stop processing further plugins - first one takes precedence.
+		a.runPlugin(plugin, page, row, ids, rows)
+		return true
+	}
+
+	if startsSequence {
+		a.pendingPluginKeys = sequence
+		a.ShowNotification(fmt.Sprintf("[yellow]%s ...[white]", strings.Join(sequence, " ")), 2*time.Second)
+		return true
+	}
+	// Any key ends an unfinished sequence, without being used for anything else
+	return len(sequence) > 1
+}
+
+// runPlugin renders the command of the plugin for the target rows and opens it in the command
+// modal. Fields of the row under the cursor are available in the template, or of the first target
+// if the cursor is on the header row.
+func (a *App) runPlugin(plugin config.PluginConfig, page string, row map[string]string, ids []string, rows []map[string]string) {
+	if row == nil {
+		row = rows[0]
+	}
+	options := CommandOptions{
+		ExecuteImmediately: plugin.ExecuteImmediately,
+		CloseAfterExecute:  plugin.ClosePromptAfterExecute,
+		Background:         plugin.Background,
+		Timeout:            plugin.Timeout,
+		Env:                plugin.Env,
+		Workdir:            plugin.Workdir,
+		Targets:            ids,
+	}
+	if plugin.Confirm {
+		options.Confirmation = fmt.Sprintf("Run '%s'?", plugin.Name)
+	}
+	a.ShowCommandModal(a.pluginCommand(plugin, row, ids, rows, page), page, options)
+}
+
+// pluginCommand renders the command of the plugin. With the 'each' selection, the command is
+// rendered for each row, and the commands run one after another until one fails, each output
+// preceded by the id of the row.
+func (a *App) pluginCommand(plugin config.PluginConfig, row map[string]string, ids []string, rows []map[string]string, page string) string {
+	separator := plugin.IdSeparator
+	if separator == "" {
+		separator = config.DEFAULT_PLUGIN_ID_SEPARATOR
+	}
+	if plugin.Selection != config.PLUGIN_SELECTION_EACH {
+		return a.ParsePluginCommand(plugin.Command, pluginTemplateData(row, ids, rows, separator), page)
+	}
+
+	var commands []string
+	for i, row := range rows {
+		commands = append(commands, fmt.Sprintf(
+			"echo %s && ( %s )",
+			model.ShellQuote(fmt.Sprintf("==> %s <==", ids[i])),
+			a.ParsePluginCommand(plugin.Command, pluginTemplateData(row, ids, rows, separator), page),
+		))
+	}
+	return strings.Join(commands, " && ")
+}
+
+// pluginTargets returns the ids and fields of the rows the plugin runs for. Plugins with a
+// multi-row selection run for the selected rows that they apply to, in the order of the table,
+// and other plugins, or if no selected row applies, for the row under the cursor if it applies.
+// Selected rows that are no longer in the data, e.g. finished jobs, are left out.
+func pluginTargets(plugin config.PluginConfig, rowId string, selection map[string]bool, data *model.TableData, tableOrder []string) (ids []string, rows []map[string]string, err error) {
+	addIfApplies := func(id string, row map[string]string, err error) error {
+		if err != nil {
+			return nil
+		}
+		applies, err := pluginAppliesToRow(plugin, row)
+		if applies {
+			ids = append(ids, id)
+			rows = append(rows, row)
+		}
+		return err
+	}
+
+	if plugin.Selection != "" && plugin.Selection != config.PLUGIN_SELECTION_SINGLE && len(selection) > 0 {
+		// Selected rows hidden by filters or the search come after those shown, in the order of the data
+		var ordered []string
+		for _, key := range tableOrder {
+			if selection[key] && !slices.Contains(ordered, key) {
+				ordered = append(ordered, key)
+			}
+		}
+		for _, dataRow := range data.Rows {
+			if key := data.RowKey(dataRow); key != "" && selection[key] && !slices.Contains(ordered, key) {
+				ordered = append(ordered, key)
 			}
+		}
+		for _, key := range ordered {
+			if err := addIfApplies(data.GetRowAsMapByKey(key)); err != nil {
+				return nil, nil, err
+			}
+		}
+		if len(ids) > 0 {
+			return ids, rows, nil
+		}
+	}
 
-			parsedCommand := a.ParsePluginCommand(plugin.Command, rowData, page)
+	if rowId == "" {
+		return nil, nil, nil
+	}
+	row, err := data.GetRowAsMapById(rowId)
+	if err := addIfApplies(rowId, row, err); err != nil {
+		return nil, nil, err
+	}
+	return ids, rows, nil
+}
 
-			a.ShowCommandModal(parsedCommand, page, plugin.ExecuteImmediately, plugin.ClosePromptAfterExecute)
+// pageData returns the data of the table on the page, or no data if the page has no table
+func (a *App) pageData(page string) *model.TableData {
+	provider := a.GetProviderForPage(page)
+	if provider == nil {
+		return model.EmptyTableData()
+	}
+	return provider.Data()
+}
 
-			// Stop processing further plugins - first one takes precedence.
-			break
+// tableOrder returns the keys of the rows of the current view, in the order shown, see model.TableData.RowKey
+func (a *App) tableOrder() []string {
+	view := a.GetCurrentStuiView()
+	if view == nil || view.data == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(view.renderedRows))
+	for _, row := range view.renderedRows {
+		if len(row) > 0 {
+			keys = append(keys, view.data.RowKey(row))
 		}
 	}
+	return keys
 }
 
-func (a *App) ParsePluginCommand(command string, data map[string]string, page string) string {
+// rowData returns the fields of the row with the given id on the page, or nil if there is none
+func (a *App) rowData(page string, rowId string) map[string]string {
+	provider := a.GetProviderForPage(page)
+	if provider == nil || rowId == "" {
+		return nil
+	}
+	row, err := provider.Data().GetRowAsMapById(rowId)
+	if err != nil {
+		logger.Printf("could not get data for this row")
+		return nil
+	}
+	return row
+}
+
+// pluginAppliesToRow checks the 'when' condition of the plugin. Plugins with a condition
+// don't apply if there is no row.
+func pluginAppliesToRow(plugin config.PluginConfig, row map[string]string) (bool, error) {
+	if plugin.When == "" {
+		return true, nil
+	}
+	if row == nil {
+		return false, nil
+	}
+	return model.MatchesCondition(plugin.When, row)
+}
+
+// pluginTemplateData has the fields of a row, plus '.Rows' with all rows the plugin runs for,
+// and '.Ids' with their ids joined by the separator
+func pluginTemplateData(row map[string]string, ids []string, rows []map[string]string, separator string) map[string]any {
+	data := map[string]any{}
+	for key, value := range row {
+		data[key] = value
+	}
+	data["Rows"] = rows
+	data["Ids"] = strings.Join(ids, separator)
+	return data
+}
+
+func (a *App) ParsePluginCommand(command string, data map[string]any, page string) string {
 	tmpl, err := template.New("command").Parse(command)
 	if err != nil {
 		return fmt.Sprintf("invalid template: %v", err)
@@ -60,16 +232,6 @@ func (a *App) ParsePluginCommand(command string, data map[string]string, page st
 	return output.String()
 }
 
-// AsKey maps a string representation of a key to a tcell key.
-func asKey(key string) tcell.Key {
-	for k, v := range tcell.KeyNames {
-		if key == v {
-			return k
-		}
-	}
-	return NO_KEY_FOUND
-}
-
 func getPluginsForPage(page string) []config.PluginConfig {
 	plugins := []config.PluginConfig{}
 	for _, plugin := range config.ConfigFile.Plugins {
@@ -81,28 +243,31 @@ func getPluginsForPage(page string) []config.PluginConfig {
 	return plugins
 }
 
-// Update helper keybinds
-func GetKeyboardShortcutHelperForPage(page string) string {
+// Update helper keybinds, leaving out plugins that don't apply to the given row
+func GetKeyboardShortcutHelperForPage(page string, row map[string]string) string {
 	plugins := getPluginsForPage(page)
 	if len(plugins) == 0 {
 		return ""
 	}
-	helper := "CUSTOM PLUGIN SHORTCUTS (in current view only)"
+	helper := "CUSTOM PLUGIN SHORTCUTS (in current view and row only)"
 
 	for _, plugin := range plugins {
+		applies, err := pluginAppliesToRow(plugin, row)
+		if err == nil && !applies {
+			continue
+		}
 
 		// Figure out the nice print format for the key
-		// If it's missing, this is where we can inform the user.
-		keyString := plugin.Shortcut
-		if asKey(plugin.Shortcut) == NO_KEY_FOUND {
-			keyString = "(N/A)"
+		// If it's invalid, this is where we can inform the user.
+		line := fmt.Sprintf("%-9s%s (%s)", plugin.Shortcut, plugin.Name, plugin.Command)
+		if _, err := config.ParseKeySequence(plugin.Shortcut); err != nil {
+			line = fmt.Sprintf("%-9s%s (%s) [red]invalid shortcut '%s': %v[white]", "(N/A)", plugin.Name, plugin.Command, plugin.Shortcut, err)
+		}
+		if err != nil {
+			line = fmt.Sprintf("%s [red]invalid 'when': %v[white]", line, err)
 		}
 
-		helper = fmt.Sprintf(
-			"%s\n%s",
-			helper,
-			fmt.Sprintf("%-9s%s (%s)", keyString, plugin.Name, plugin.Command),
-		)
+		helper = fmt.Sprintf("%s\n%s", helper, line)
 
 	}
 	return helper
@@ -0,0 +1,165 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testJobs has jobs in the order they were fetched, which differs from their order by id
+func testJobs() *model.TableData {
+	return &model.TableData{
+		Headers: &[]config.ColumnConfig{{RawName: "JobId"}, {RawName: "JobState"}, {RawName: "UserId"}},
+		Rows: [][]string{
+			{"1000", "RUNNING", "alice"},
+			{"998", "PENDING", "bob"},
+			{"10", "RUNNING", "carol"},
+			{"1000_2", "RUNNING", "alice"},
+		},
+	}
+}
+
+func TestPluginTargets(t *testing.T) {
+	selected := map[string]bool{"1000": true, "10": true, "998": true, "gone": true}
+	tableOrder := []string{"10", "998", "1000", "1000_2"} // Sorted by id in the view
+
+	tests := []struct {
+		name       string
+		plugin     config.PluginConfig
+		rowId      string
+		selection  map[string]bool
+		tableOrder []string
+		ids        []string
+	}{
+		{
+			name:   "row under the cursor",
+			plugin: config.PluginConfig{},
+			rowId:  "998",
+			ids:    []string{"998"},
+		},
+		{
+			name:      "single plugin ignores the selection",
+			plugin:    config.PluginConfig{Selection: config.PLUGIN_SELECTION_SINGLE},
+			rowId:     "998",
+			selection: selected,
+			ids:       []string{"998"},
+		},
+		{
+			name:       "selection in table order",
+			plugin:     config.PluginConfig{Selection: config.PLUGIN_SELECTION_MULTIPLE},
+			rowId:      "1000_2",
+			selection:  selected,
+			tableOrder: tableOrder,
+			ids:        []string{"10", "998", "1000"},
+		},
+		{
+			name:       "selection hidden by the search after the rows shown",
+			plugin:     config.PluginConfig{Selection: config.PLUGIN_SELECTION_EACH},
+			selection:  selected,
+			tableOrder: []string{"998"},
+			ids:        []string{"998", "1000", "10"},
+		},
+		{
+			name:      "selection on the header row",
+			plugin:    config.PluginConfig{Selection: config.PLUGIN_SELECTION_MULTIPLE},
+			rowId:     "",
+			selection: selected,
+			ids:       []string{"1000", "998", "10"},
+		},
+		{
+			name:       "selection filtered by the condition",
+			plugin:     config.PluginConfig{Selection: config.PLUGIN_SELECTION_EACH, When: "JobState == RUNNING"},
+			rowId:      "998",
+			selection:  selected,
+			tableOrder: tableOrder,
+			ids:        []string{"10", "1000"},
+		},
+		{
+			name:      "row under the cursor if no selected row applies",
+			plugin:    config.PluginConfig{Selection: config.PLUGIN_SELECTION_MULTIPLE, When: "UserId == alice"},
+			rowId:     "1000_2",
+			selection: map[string]bool{"998": true},
+			ids:       []string{"1000_2"},
+		},
+		{
+			name:   "row under the cursor that doesn't apply",
+			plugin: config.PluginConfig{When: "JobState == RUNNING"},
+			rowId:  "998",
+			ids:    nil,
+		},
+		{
+			name:   "header row without a selection",
+			plugin: config.PluginConfig{},
+			rowId:  "",
+			ids:    nil,
+		},
+		{
+			name:      "selected rows that are gone",
+			plugin:    config.PluginConfig{Selection: config.PLUGIN_SELECTION_MULTIPLE},
+			selection: map[string]bool{"gone": true},
+			ids:       nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ids, rows, err := pluginTargets(test.plugin, test.rowId, test.selection, testJobs(), test.tableOrder)
+			require.NoError(t, err)
+			assert.Equal(t, test.ids, ids)
+			require.Len(t, rows, len(ids))
+			for i, row := range rows {
+				assert.Equal(t, ids[i], row["JobId"])
+			}
+		})
+	}
+
+	_, _, err := pluginTargets(config.PluginConfig{When: "JobState ="}, "998", nil, testJobs(), nil)
+	assert.Error(t, err)
+}
+
+// In the federated view, selecting a job selects it only on its own cluster
+func TestPluginTargetsOnSeveralClusters(t *testing.T) {
+	data := &model.TableData{
+		Headers: &[]config.ColumnConfig{{RawName: "JobId"}, {RawName: "Cluster"}, {RawName: "UserId"}},
+		Rows: [][]string{
+			{"1000", "alpha", "alice"},
+			{"1000", "beta", "bob"},
+			{"998", "beta", "carol"},
+		},
+	}
+	plugin := config.PluginConfig{Selection: config.PLUGIN_SELECTION_MULTIPLE}
+	selection := map[string]bool{"beta/1000": true, "beta/998": true}
+
+	ids, rows, err := pluginTargets(plugin, "", selection, data, []string{"beta/998", "alpha/1000", "beta/1000"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"998", "1000"}, ids)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "bob", rows[1]["UserId"])
+}
+
+func TestPluginCommand(t *testing.T) {
+	a := &App{}
+	data := testJobs()
+	ids := []string{"10", "1000"}
+	var rows []map[string]string
+	for _, id := range ids {
+		row, err := data.GetRowAsMapById(id)
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+
+	plugin := config.PluginConfig{Selection: config.PLUGIN_SELECTION_MULTIPLE, Command: "scancel {{.Ids}} # {{.UserId}}"}
+	assert.Equal(t, "scancel 10,1000 # carol", a.pluginCommand(plugin, rows[0], ids, rows, JOBS_PAGE))
+
+	plugin.IdSeparator = " "
+	assert.Equal(t, "scancel 10 1000 # carol", a.pluginCommand(plugin, rows[0], ids, rows, JOBS_PAGE))
+
+	// Each row runs after the previous one has succeeded
+	plugin = config.PluginConfig{Selection: config.PLUGIN_SELECTION_EACH, Command: "scontrol hold {{.JobId}}; echo {{.UserId}}"}
+	assert.Equal(t,
+		"echo '==> 10 <==' && ( scontrol hold 10; echo carol ) && echo '==> 1000 <==' && ( scontrol hold 1000; echo alice )",
+		a.pluginCommand(plugin, nil, ids, rows, JOBS_PAGE),
+	)
+}
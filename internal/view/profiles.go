@@ -0,0 +1,116 @@
+package view
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/logger"
+	"github.com/antvirf/stui/internal/model"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const DEFAULT_PROFILE_OPTION = "(default)"
+
+// ShowProfileSelector opens a list of the cluster profiles defined in config files
+func (a *App) ShowProfileSelector() {
+	names := config.ProfileNames()
+	if len(names) == 0 {
+		a.ShowNotification("[yellow]No profiles defined in config files[white]", 2*time.Second)
+		return
+	}
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedStyle(tcell.StyleDefault.Background(rowCursorColorBackground).Foreground(rowCursorColorForeground)).
+		SetMainTextColor(generalTextColor)
+	list.SetBackgroundColor(generalBackgroundColor)
+
+	pageName, current := "", 0
+	for i, name := range append([]string{DEFAULT_PROFILE_OPTION}, names...) {
+		profile := name
+		if i == 0 {
+			profile = ""
+		}
+		text := name
+		if profile == config.ActiveProfile {
+			text += " (active)"
+			current = i
+		}
+		list.AddItem(tview.Escape(text), "", 0, func() {
+			a.Pages.RemovePage(pageName)
+			a.SwitchProfile(profile)
+		})
+	}
+
+	list.SetCurrentItem(current)
+
+	pageName = a.showModalPopup("Switch cluster profile", list, 4, len(names)+3, 4)
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			a.Pages.RemovePage(pageName)
+			_, frontPage := a.Pages.GetFrontPage()
+			a.App.SetFocus(frontPage)
+			return nil
+		}
+		return event
+	})
+}
+
+// SwitchProfile connects to the cluster of the given profile, and reloads all views with
+// fresh providers. On failure, the previous profile stays active. The switch runs in the UI thread
+// like all fetches, and the periodic refresh is stopped meanwhile, as the profile may change its interval.
+func (a *App) SwitchProfile(name string) {
+	label := name
+	if label == "" {
+		label = DEFAULT_PROFILE_OPTION
+	}
+	a.ShowNotification(fmt.Sprintf("[green]Switching to profile %s[white]", label), 2*time.Second)
+
+	go a.App.QueueUpdateDraw(func() {
+		a.stopPeriodicRefresh()
+		defer a.startPeriodicRefresh()
+
+		if err := config.SwitchProfile(name); err != nil {
+			a.ShowNotification(fmt.Sprintf("[red]Failed to switch profile: %v[white]", err), 5*time.Second)
+			return
+		}
+		logger.Printf("Switched to profile '%s' on cluster '%s'", label, config.ClusterName)
+
+		a.initializeProviders()
+		a.NodesView.SetProvider(a.NodesProvider)
+		a.JobsView.SetProvider(a.JobsProvider)
+		a.SacctView.SetProvider(a.SacctProvider)
+		a.SacctMgrView.SetProvider(a.SacctMgrProvider)
+
+		// Filters chosen for the previous cluster may not apply, so reset them
+		a.PartitionsData = a.PartitionsProvider.Data()
+		a.setupPartitionSelectorOptions()
+		a.NodeStateSelector.SetCurrentOption(0)
+		a.JobStateSelector.SetCurrentOption(0)
+		a.SacctTimeRangeInput.SetText(model.CurrentSacctQuery().TimeRangeString())
+		a.SacctFiltersInput.SetText(model.CurrentSacctQuery().FiltersString())
+
+		a.updateMainTitle()
+		a.updateAccountingTabs()
+		a.NodesView.Render()
+		a.JobsView.Render()
+		a.SacctView.Render()
+		a.SacctMgrView.Render()
+		a.SchedView.SetText(a.SdiagProvider.Data().Data)
+
+		switch page := a.GetCurrentPageName(); {
+		case !config.SacctEnabled && (page == SACCT_PAGE || page == SACCTMGR_PAGE):
+			a.showNodesPage()
+		default:
+			if view := a.GetCurrentStuiView(); view != nil {
+				a.setupSortSelectorOptions(view.provider, view.primarySortColumn())
+			}
+			_, frontPage := a.Pages.GetFrontPage()
+			a.App.SetFocus(frontPage)
+		}
+	})
+}
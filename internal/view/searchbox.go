@@ -70,6 +70,9 @@ func (a *App) ShowSearchBox(grid *tview.Grid) {
 	case SACCT_PAGE:
 		a.SacctView.SetSearchEnabled(true)
 		table = a.SacctView.Table
+	case TASKS_PAGE:
+		a.TasksView.SetSearchEnabled(true)
+		table = a.TasksView.Table
 	}
 
 	// Clear and rebuild the grid with search box
@@ -104,6 +107,10 @@ func (a *App) HideSearchBox() {
 		a.SacctView.SetSearchEnabled(false)
 		grid = a.SacctView.Grid
 		table = a.SacctView.Table
+	case TASKS_PAGE:
+		a.TasksView.SetSearchEnabled(false)
+		grid = a.TasksView.Grid
+		table = a.TasksView.Table
 	}
 
 	// Stop any pending search updates
@@ -34,6 +34,7 @@ func (a *App) SetupPartitionSelector() {
 }
 
 func (a *App) setupPartitionSelectorOptions() {
+	a.PartitionSelector.SetOptions(nil, nil)
 	for index, partition := range a.PartitionsData.Rows {
 		if index == 0 {
 			a.PartitionSelector.AddOption(
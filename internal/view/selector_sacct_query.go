@@ -0,0 +1,83 @@
+package view
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/antvirf/stui/internal/model"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Input fields for the sacct time range and server-side filters. Unlike the other
+// selectors, these change what is fetched from sacct, so applying them triggers a fetch.
+func (a *App) SetupSacctQueryInputs() {
+	a.SacctTimeRangeInput = newSacctQueryInputField("(t) Time range:")
+	a.SacctTimeRangeInput.SetText(model.CurrentSacctQuery().TimeRangeString())
+	a.SacctTimeRangeInput.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			since, startTime, endTime, err := model.ParseSacctTimeRange(a.SacctTimeRangeInput.GetText())
+			if err != nil {
+				a.ShowNotification(fmt.Sprintf("[red]%v[white]", err), 3*time.Second)
+				return
+			}
+			if since > 0 {
+				config.LoadSacctDataFrom = since
+			}
+			config.SacctStartTime, config.SacctEndTime = startTime, endTime
+			a.applySacctQuery()
+		case tcell.KeyEsc:
+			a.SacctTimeRangeInput.SetText(model.CurrentSacctQuery().TimeRangeString())
+			_, frontPage := a.Pages.GetFrontPage()
+			a.App.SetFocus(frontPage)
+		}
+	})
+
+	a.SacctFiltersInput = newSacctQueryInputField("(f) Filters:")
+	a.SacctFiltersInput.SetText(model.CurrentSacctQuery().FiltersString())
+	a.SacctFiltersInput.SetPlaceholder("e.g. user=alice state=FAILED")
+	a.SacctFiltersInput.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			users, accounts, states, err := model.ParseSacctFilters(a.SacctFiltersInput.GetText())
+			if err != nil {
+				a.ShowNotification(fmt.Sprintf("[red]%v[white]", err), 3*time.Second)
+				return
+			}
+			config.SacctUsers, config.SacctAccounts, config.SacctStates = users, accounts, states
+			a.applySacctQuery()
+		case tcell.KeyEsc:
+			a.SacctFiltersInput.SetText(model.CurrentSacctQuery().FiltersString())
+			_, frontPage := a.Pages.GetFrontPage()
+			a.App.SetFocus(frontPage)
+		}
+	})
+}
+
+func newSacctQueryInputField(label string) *tview.InputField {
+	return tview.NewInputField().
+		SetLabel(PadSelectorTitle(label)).
+		SetLabelStyle(tcell.StyleDefault.Foreground(dropdownForegroundColor)).
+		SetFieldWidth(0).
+		SetFieldBackgroundColor(dropdownBackgroundColor).
+		SetPlaceholderStyle(tcell.StyleDefault.Background(dropdownBackgroundColor).Foreground(generalTextColor).Dim(true))
+}
+
+// applySacctQuery refetches sacct data with the current time range and filters
+func (a *App) applySacctQuery() {
+	query := model.CurrentSacctQuery()
+	a.SacctTimeRangeInput.SetText(query.TimeRangeString())
+	a.SacctFiltersInput.SetText(query.FiltersString())
+
+	_, frontPage := a.Pages.GetFrontPage()
+	a.App.SetFocus(frontPage)
+	a.ShowNotification(
+		fmt.Sprintf("[green]Loading sacct data for %s %s[white]", query.TimeRangeString(), query.FiltersString()),
+		2*time.Second,
+	)
+	go a.App.QueueUpdateDraw(func() {
+		a.SacctView.FetchAndRender()
+	})
+}
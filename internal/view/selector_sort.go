@@ -22,59 +22,71 @@ func (a *App) SetupSortSelector() {
 	a.SortSelector.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
 		case tcell.KeyEsc:
+			a.resetSortSelectorMode()
 			_, frontPage := a.Pages.GetFrontPage()
 			a.App.SetFocus(frontPage)
 			return nil
 		}
 		return event
 	})
+	// Leaving the selector, e.g. with a click elsewhere, ends the 'then by' mode. Opening the list of
+	// the selector moves the focus to the list, which doesn't.
+	a.SortSelector.SetBlurFunc(func() {
+		if !a.SortSelector.IsOpen() {
+			a.resetSortSelectorMode()
+		}
+	})
 }
 
 func (a *App) setupSortSelectorOptions(provider model.DataProvider[*model.TableData], selectedColumn int) {
-	a.SortSelector.SetCurrentOption(selectedColumn)
-	a.SortSelector.SetOptions([]string{}, nil)
-	columns := provider.Data().Headers
-	for index, column := range *columns {
-		if index == 0 {
-			a.SortSelector.AddOption(
-				config.NO_SORT_OPTION,
-				a.applySortSelector(-1),
-			)
-		}
-		a.SortSelector.AddOption(
-			column.DisplayName,
-			a.applySortSelector(index),
-		)
+	options := []string{config.NO_SORT_OPTION}
+	for _, column := range *provider.Data().Headers {
+		options = append(options, column.DisplayName)
 	}
+	a.SortSelector.SetOptions(options, nil)
+	a.showSortSelectorColumn(selectedColumn)
+}
 
-	if selectedColumn == -1 {
-		a.SortSelector.SetCurrentOption(0)
-	} else {
-		a.SortSelector.SetCurrentOption(selectedColumn + 1)
-	}
+// showSortSelectorColumn shows the given column in the sort selector, without changing the sort
+func (a *App) showSortSelectorColumn(column int) {
+	a.SortSelector.SetSelectedFunc(nil)
+	a.SortSelector.SetCurrentOption(column + 1) // First option is for no sorting
+	a.SortSelector.SetSelectedFunc(a.applySortSelector)
 }
 
-func (a *App) applySortSelector(column int) func() {
-	return func() {
-		view := a.GetCurrentStuiView()
-		if view == nil {
-			return
-		}
+// FocusSortSelector opens the sort selector. With thenBy, the chosen column is added as a
+// secondary sort column instead of replacing the current sort.
+func (a *App) FocusSortSelector(thenBy bool) {
+	a.resetSortSelectorMode()
+	a.sortSelectorThenBy = thenBy
+	if thenBy {
+		a.SortSelector.SetLabel(PadSelectorTitle("(O) Then by:"))
+	}
+	a.App.SetFocus(a.SortSelector)
+}
 
-		view.sortColumn = column
+func (a *App) resetSortSelectorMode() {
+	a.sortSelectorThenBy = false
+	a.SortSelector.SetLabel(PadSelectorTitle("(o) Sort by:"))
+}
 
-		// Reverse sort direction if the same column is selected again
-		switch view.sortDirection {
-		case SORT_NONE:
-			view.sortDirection = SORT_ASC
-		case SORT_ASC:
-			view.sortDirection = SORT_DESC
-		case SORT_DESC:
-			view.sortDirection = SORT_ASC
-		}
+func (a *App) applySortSelector(_ string, index int) {
+	view := a.GetCurrentStuiView()
+	if view == nil {
+		return
+	}
 
-		a.RenderCurrentView()
-		_, frontPage := a.Pages.GetFrontPage()
-		a.App.SetFocus(frontPage)
+	// Selecting the same column again reverses its sort direction
+	column := index - 1
+	if a.sortSelectorThenBy {
+		view.thenSortBy(column)
+	} else {
+		view.sortBy(column)
 	}
+	a.resetSortSelectorMode()
+	a.showSortSelectorColumn(view.primarySortColumn())
+
+	a.RenderCurrentView()
+	_, frontPage := a.Pages.GetFrontPage()
+	a.App.SetFocus(frontPage)
 }
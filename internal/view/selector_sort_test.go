@@ -0,0 +1,22 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortSelectorThenByEndsOnBlur(t *testing.T) {
+	a := &App{App: tview.NewApplication()}
+	a.SetupSortSelector()
+
+	a.FocusSortSelector(true)
+	assert.True(t, a.sortSelectorThenBy)
+	assert.Contains(t, a.SortSelector.GetLabel(), "Then by")
+
+	// Leaving the selector without choosing a column ends the 'then by' mode
+	a.App.SetFocus(tview.NewBox())
+	assert.False(t, a.sortSelectorThenBy)
+	assert.Contains(t, a.SortSelector.GetLabel(), "Sort by")
+}
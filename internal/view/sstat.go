@@ -0,0 +1,59 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/antvirf/stui/internal/config"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ShowJobUsage shows live usage from sstat for each step of the given jobs.
+// Jobs are given by their keys in the selection, see model.TableData.RowKey. Jobs that are not
+// running are skipped, as sstat has no data for them.
+func (a *App) ShowJobUsage(jobKeys []string) {
+	data := a.JobsProvider.Data()
+	var runningJobIDs []string
+	for _, row := range data.Rows {
+		for _, jobKey := range jobKeys {
+			if data.RowKey(row) == jobKey && strings.Contains(row[config.JobsViewColumnsStateIndex], "RUNNING") {
+				runningJobIDs = append(runningJobIDs, row[0])
+			}
+		}
+	}
+	if len(runningJobIDs) == 0 {
+		a.ShowNotification("[red]sstat: no running jobs selected[white]", 2*time.Second)
+		return
+	}
+
+	a.SstatProvider.SetJobIds(runningJobIDs)
+	a.SstatProvider.Fetch()
+	if err := a.SstatProvider.LastError(); err != nil {
+		a.ShowModalPopupString("Job Usage [sstat]", fmt.Sprintf("Error fetching job usage:\n%s", err.Error()))
+		return
+	}
+
+	usage := a.SstatProvider.FilteredData()
+	table := tview.NewTable()
+	table.SetFixed(1, 1)
+	table.SetSelectable(true, false)
+	table.SetBorderPadding(0, 0, 1, 1)
+	for j, header := range *usage.Headers {
+		table.SetCell(0, j, tview.NewTableCell(header.DisplayName).
+			SetAttributes(tcell.AttrBold).
+			SetTextColor(selectionColor).
+			SetSelectable(false))
+	}
+	for i, row := range usage.Rows {
+		for j, cell := range row {
+			table.SetCell(i+1, j, tview.NewTableCell(cell).SetMaxWidth(config.MaximumColumnWidth))
+		}
+	}
+
+	a.ShowModalPopupTable(
+		fmt.Sprintf("Job Usage [sstat]: %s", strings.Join(runningJobIDs, ", ")),
+		table,
+	)
+}
@@ -2,8 +2,8 @@ package view
 
 import (
 	"fmt"
-	"regexp"
-	"sort"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +20,7 @@ func NewStuiView(
 	updateTitleFunc func(string) *tview.Box,
 	errorNotifyFunc func(string),
 	dataStateNotifyFunc func(string),
+	summaryNotifyFunc func(string),
 	cellClickFunction func(string),
 	headerClickFunction func(int) *tview.DropDown,
 	searchStringPointer *string,
@@ -32,11 +33,10 @@ func NewStuiView(
 		filter:                        "",
 		searchEnabled:                 false,
 		searchPattern:                 searchStringPointer,
-		sortColumn:                    -1,        // No column sorted by default
-		sortDirection:                 SORT_NONE, // Default to no sort
 		updateTitleFunction:           updateTitleFunc,
 		errorNotificationFunction:     errorNotifyFunc,
 		dataStateNotificationFunction: dataStateNotifyFunc,
+		summaryNotificationFunction:   summaryNotifyFunc,
 		cellClickFunction:             cellClickFunction,
 		headerClickFunction:           headerClickFunction,
 	}
@@ -85,27 +85,72 @@ type StuiView struct {
 	searchEnabled bool
 	searchPattern *string // Pointer to a shared string
 
-	// Sorting state
-	sortColumn    int // Index of column being sorted (-1 for none)
-	sortDirection int // -1 for descending, 1 for ascending
+	// Sorting state, kept across refreshes
+	sortKeys []model.SortKey // Columns being sorted by, in order of priority
 
 	// Callback functions
 	updateTitleFunction           func(string) *tview.Box
 	errorNotificationFunction     func(string)
 	dataStateNotificationFunction func(string)
+	summaryNotificationFunction   func(string)
 	cellClickFunction             func(string)
 	headerClickFunction           func(int) *tview.DropDown
+	summaryFunction               func([]map[string]string) string
 
 	// Data components
-	provider model.DataProvider[*model.TableData]
-	data     *model.TableData
-	filter   string
+	provider     model.DataProvider[*model.TableData]
+	data         *model.TableData
+	renderedRows [][]string // Rows as last rendered, after filters, search and sorting
+	filter       string
 }
 
 func (s *StuiView) SetFilter(filter string) {
 	s.filter = filter
 }
 
+// RenderedData returns the rows as currently shown in the table, after filters, search and sorting
+func (s *StuiView) RenderedData() *model.TableData {
+	if s.data == nil {
+		return model.EmptyTableData()
+	}
+	return &model.TableData{
+		Headers: s.data.Headers,
+		Rows:    s.renderedRows,
+	}
+}
+
+// cursorRowValue returns the value of the given column in the row at the cursor, as rendered, or
+// an empty string if there is no such row or column
+func (s *StuiView) cursorRowValue(rawName string) string {
+	row, _ := s.Table.GetSelection()
+	if s.data == nil || row < 1 || row > len(s.renderedRows) {
+		return ""
+	}
+	for col, header := range *s.data.Headers {
+		if header.RawName == rawName && col < len(s.renderedRows[row-1]) {
+			return s.renderedRows[row-1][col]
+		}
+	}
+	return ""
+}
+
+// cursorRowKey returns the key of the row at the cursor, as used in the selection, or an empty
+// string if there is no such row
+func (s *StuiView) cursorRowKey() string {
+	row, _ := s.Table.GetSelection()
+	if s.data == nil || row < 1 || row > len(s.renderedRows) {
+		return ""
+	}
+	return s.data.RowKey(s.renderedRows[row-1])
+}
+
+// SetProvider replaces the data provider, e.g. after switching profiles. Selection and sorting are cleared.
+func (s *StuiView) SetProvider(provider model.DataProvider[*model.TableData]) {
+	s.provider = provider
+	s.Selection = make(map[string]bool)
+	s.sortKeys = nil
+}
+
 func (s *StuiView) SetTitleHeader(v string) {
 	s.titleHeader = v
 }
@@ -114,6 +159,49 @@ func (s *StuiView) SetSearchEnabled(value bool) {
 	s.searchEnabled = value
 }
 
+// SetSummaryFunction sets the function used to compute summary statistics over the shown rows,
+// from the summary fields of the data. Views without one clear the summary in the header.
+func (s *StuiView) SetSummaryFunction(summaryFunction func([]map[string]string) string) {
+	s.summaryFunction = summaryFunction
+}
+
+// primarySortColumn returns the column with the highest sort priority, or -1 if not sorted
+func (s *StuiView) primarySortColumn() int {
+	if len(s.sortKeys) == 0 {
+		return -1
+	}
+	return s.sortKeys[0].Column
+}
+
+// sortBy sorts by the given column only, reversing the direction if it already is the primary
+// sort column. A column of -1 clears the sort.
+func (s *StuiView) sortBy(column int) {
+	switch {
+	case column < 0:
+		s.sortKeys = nil
+	case s.primarySortColumn() == column:
+		s.sortKeys = []model.SortKey{{Column: column, Descending: !s.sortKeys[0].Descending}}
+	default:
+		s.sortKeys = []model.SortKey{{Column: column}}
+	}
+}
+
+// thenSortBy adds the given column as the lowest priority sort column, or reverses its direction
+// if it is already sorted by. A column of -1 clears the sort.
+func (s *StuiView) thenSortBy(column int) {
+	if column < 0 {
+		s.sortKeys = nil
+		return
+	}
+	for i := range s.sortKeys {
+		if s.sortKeys[i].Column == column {
+			s.sortKeys[i].Descending = !s.sortKeys[i].Descending
+			return
+		}
+	}
+	s.sortKeys = append(s.sortKeys, model.SortKey{Column: column})
+}
+
 func (s *StuiView) Render() {
 	startTime := time.Now()
 	s.data = s.provider.FilteredData()
@@ -127,52 +215,52 @@ func (s *StuiView) Render() {
 
 	searchFilterTime := int64(0)
 	filteredRows := s.data.Rows
+	summaryFields := s.data.SummaryFields
 	if s.searchEnabled && *s.searchPattern != "" {
-		filteredCount = 0 // Will be updated in the filtering loop below
+		filteredCount = 0 // Updated below if the search pattern is valid
 		searchFilterStartTime := time.Now()
 		filteredRows = [][]string{}
+		summaryFields = nil
 
-		pattern, err := regexp.Compile("(?i)" + *s.searchPattern)
+		searched, err := s.data.ApplySearch(*s.searchPattern)
 		if err != nil {
 			s.errorNotificationFunction(fmt.Sprintf("[red]Invalid search pattern: %v[white]", err))
 		} else {
-			// Preallocate slice with reasonable capacity
-			filteredRows = make([][]string, 0, len(s.data.Rows)/2)
-
-			for i, row := range s.data.Rows {
-				// Check the row as a single string - this allows for regex across columns
-				matched := pattern.MatchString(s.data.RowsAsSingleStrings[i])
-
-				if matched {
-					filteredRows = append(filteredRows, row)
-					filteredCount++
-				}
-			}
+			filteredRows = searched.Rows
+			summaryFields = searched.SummaryFields
+			filteredCount = searched.Length()
 		}
 		searchFilterTime = time.Since(searchFilterStartTime).Milliseconds()
 	}
 
-	// Sort rows if sort column is set
-	if s.sortColumn >= 0 && len(filteredRows) > 0 {
-		sort.Slice(filteredRows, func(i, j int) bool {
-			if s.sortDirection > 0 {
-				return filteredRows[i][s.sortColumn] < filteredRows[j][s.sortColumn]
-			}
-			return filteredRows[i][s.sortColumn] > filteredRows[j][s.sortColumn]
-		})
+	// Sort rows if sort columns are set, ignoring any that are not in the current columns
+	var sortKeys []model.SortKey
+	for _, key := range s.sortKeys {
+		if key.Column < len(*s.data.Headers) {
+			sortKeys = append(sortKeys, key)
+		}
+	}
+	if len(sortKeys) > 0 && len(filteredRows) > 0 {
+		// Numbers, durations, timestamps and job IDs are compared by value rather than as text
+		model.SortRows(filteredRows, sortKeys)
 	}
+	s.renderedRows = filteredRows
 
 	for col, header := range *s.data.Headers {
 		// If header is a divided type, clean it up
 		headerName := header.DisplayName
 
-		// Add sort indicator if this is the sorted column
-		if col == s.sortColumn {
-			if s.sortDirection > 0 {
-				headerName = "↑ " + headerName
-			} else {
-				headerName = "↓ " + headerName
+		// Add sort indicator if this is a sorted column, numbered by priority if there are several
+		sortPriority := slices.IndexFunc(sortKeys, func(key model.SortKey) bool { return key.Column == col })
+		if sortPriority >= 0 {
+			marker := "↑"
+			if sortKeys[sortPriority].Descending {
+				marker = "↓"
+			}
+			if len(sortKeys) > 1 {
+				marker += strconv.Itoa(sortPriority + 1)
 			}
+			headerName = marker + " " + headerName
 		}
 
 		// Pad header with spaces to maintain width
@@ -189,8 +277,8 @@ func (s *StuiView) Render() {
 			return true
 		})
 
-		// Highlight sorted column header
-		if col == s.sortColumn {
+		// Highlight sorted column headers
+		if sortPriority >= 0 {
 			cell.SetBackgroundColor(selectionColor)
 		} else {
 			cell.SetBackgroundColor(generalBackgroundColor)
@@ -232,7 +320,7 @@ func (s *StuiView) Render() {
 			}
 
 			// Highlight selected rows, or set color based on status
-			if s.Selection[rowData[0]] {
+			if s.Selection[s.data.RowKey(rowData)] {
 				cellView.SetBackgroundColor(selectionColor)
 				cellView.SetTextColor(selectionTextColor)
 				cellView.SetSelectedStyle(tcell.StyleDefault.Background(selectionHighlightColor))
@@ -286,6 +374,12 @@ func (s *StuiView) Render() {
 		s.errorNotificationFunction("")
 	}
 
+	if s.summaryFunction != nil {
+		s.summaryNotificationFunction(s.summaryFunction(summaryFields))
+	} else {
+		s.summaryNotificationFunction("")
+	}
+
 	execTime := time.Since(startTime).Milliseconds()
 	searchInfo := ""
 	if s.searchEnabled {
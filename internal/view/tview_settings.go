@@ -12,6 +12,7 @@ const (
 	THRD_ROW = 2
 	FRTH_ROW = 3
 	FFTH_ROW = 4
+	SXTH_ROW = 5
 	FRST_COL = 0
 	SCND_COL = 1
 	THRD_COL = 2
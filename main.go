@@ -1,14 +1,26 @@
 package main
 
 import (
+	"log"
+	"os"
+
 	"github.com/antvirf/stui/internal/config"
 	"github.com/antvirf/stui/internal/logger"
+	"github.com/antvirf/stui/internal/model"
 	"github.com/antvirf/stui/internal/view"
 )
 
 func main() {
 	config.Configure()
 
+	// 'stui export' writes a view to stdout without starting the UI
+	if config.ExportMode {
+		if err := model.Export(os.Stdout); err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+		return
+	}
+
 	app := view.InitializeApplication()
 	app.SetupViews()
 	app.SetupKeybinds()